@@ -31,17 +31,16 @@ type Pool struct {
 	// Running is the number of the currently running goroutines.
 	running int64
 
-	// Workers is a slice that store the avaliable workers.
-	workers []*WorkerManager
+	// Workers is a buffered channel that store the avaliable workers.
+	// Its capacity equals the capacity of the pool, so reverting a worker
+	// never blocks, and waiting for an idle worker can be used in select.
+	workers chan *WorkerManager
 
 	// Release is used to notice the pool to closed itself.
 	release int64
 
-	// Lock for synchronous operation.
-	lock sync.Mutex
-
-	// Cond for waiting to get a idle worker.
-	cond *sync.Cond
+	// Closed is closed by Release to wake up the waiting Exec.
+	closed chan struct{}
 
 	// Once makes sure releasing this pool will just be done for one time.
 	once sync.Once
@@ -52,20 +51,14 @@ type Pool struct {
 
 // NewPool generates an instance of gocon pool.
 func NewPool(size int) (*Pool, error) {
-	if size < 0 {
+	if size <= 0 {
 		return nil, ErrInvalidPoolSize
 	}
 
 	p := &Pool{
-		cap: int64(size),
-	}
-	p.cond = sync.NewCond(&p.lock)
-
-	for i := 0; i < size; i++ {
-		p.workers = append(p.workers, &WorkerManager{
-			pool: p,
-			task: make(chan func()),
-		})
+		cap:     int64(size),
+		workers: make(chan *WorkerManager, size),
+		closed:  make(chan struct{}),
 	}
 
 	return p, nil
@@ -79,7 +72,11 @@ func (p *Pool) Exec(task func()) error {
 	}
 
 	// Get idle worker and exec the task.
-	p.retrieveWorker().start(task)
+	w := p.retrieveWorker()
+	if w == nil {
+		return ErrPoolClosed
+	}
+	w.start(task)
 	return nil
 }
 
@@ -98,79 +95,82 @@ func (p *Pool) Release() error {
 	p.once.Do(func() {
 		// Close the pool
 		atomic.StoreInt64(&p.release, 1)
+		close(p.closed)
 
-		p.lock.Lock()
-		idle := p.workers
-		for i, w := range idle {
-			w.task <- nil
-			idle[i] = nil
-		}
-		p.workers = nil
-		p.lock.Unlock()
+		p.drainWorkers()
 	})
 	return nil
 }
 
-// incRunning is the running woker self subtraction.
-func (p *Pool) incRunning() {
-	atomic.AddInt64(&p.running, -1)
-}
-
-// decrunning is the running woker self add.
+// decRunning is the running woker self subtraction.
 func (p *Pool) decRunning() {
-	atomic.AddInt64(&p.running, 1)
+	atomic.AddInt64(&p.running, -1)
 }
 
+// retrieveWorker returns an idle worker, or nil if the pool is released
+// while waiting for one.
 func (p *Pool) retrieveWorker() *WorkerManager {
-	var w *WorkerManager
-
-	p.lock.Lock()
-	idle := p.workers
-	n := len(idle) - 1
-
 	// the p pool has idle workers.
-	if n >= 0 {
-		// Pop last task
-		w = idle[n]
-		idle[n] = nil
-		p.workers = idle[:n]
-		p.lock.Unlock()
-	} else if p.Running() < p.Cap() && p.Running() > 0 {
-		// 1. The p pool no idle workers.
-		// 2. Did not exceed the maximum capacity limit then create a new worker.
-		p.lock.Unlock()
-
-		w = &WorkerManager{
-			pool: p,
-			task: make(chan func()),
-		}
-	} else {
-		// 1. Exceeded the maximum limit.
-		// 2. Waiting for idle worker.
-		for {
-			// Waiting the idle worker.
-			p.cond.Wait()
-
-			// No idle worker
-			l := len(p.workers) - 1
-			if l < 0 {
-				continue
-			}
+	select {
+	case w := <-p.workers:
+		return w
+	default:
+	}
 
-			w = p.workers[l]
-			p.workers[l] = nil
-			p.workers = p.workers[:l]
+	// 1. The p pool no idle workers.
+	// 2. Did not exceed the maximum capacity limit then create a new worker.
+	for {
+		n := atomic.LoadInt64(&p.running)
+		if n >= atomic.LoadInt64(&p.cap) {
 			break
 		}
-		p.lock.Unlock()
+
+		if atomic.CompareAndSwapInt64(&p.running, n, n+1) {
+			w := &WorkerManager{
+				pool: p,
+				task: make(chan func(), workerChanCap()),
+			}
+			w.run()
+			return w
+		}
+	}
+
+	// 1. Exceeded the maximum limit.
+	// 2. Waiting for idle worker.
+	select {
+	case w := <-p.workers:
+		return w
+	case <-p.closed:
+		return nil
 	}
-	return w
 }
 
-// revertWorker is the recycling worker.
-func (p *Pool) revertWorker(woker *WorkerManager) {
-	p.lock.Lock()
-	p.workers = append(p.workers, woker)
-	p.cond.Signal()
-	p.lock.Unlock()
+// revertWorker is the recycling worker. It returns false if the pool
+// has been released and the worker should exit.
+func (p *Pool) revertWorker(worker *WorkerManager) bool {
+	if atomic.LoadInt64(&p.release) == 1 {
+		return false
+	}
+
+	// Never blocks, at most cap workers are alive.
+	p.workers <- worker
+
+	// Release may have drained the idle workers before we put this one
+	// back, so drain again to make sure no worker is left behind.
+	if atomic.LoadInt64(&p.release) == 1 {
+		p.drainWorkers()
+	}
+	return true
+}
+
+// drainWorkers stops all the idle workers.
+func (p *Pool) drainWorkers() {
+	for {
+		select {
+		case w := <-p.workers:
+			close(w.task)
+		default:
+			return
+		}
+	}
 }
@@ -0,0 +1,402 @@
+package gocon
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const benchPoolSize = 64
+
+// waitFor polls cond until it returns true or the timeout expires.
+func waitFor(timeout time.Duration, cond func() bool) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return true
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return cond()
+}
+
+// checkNoLeak fails the test if the goroutines started after base are
+// still alive once the pool has been released.
+func checkNoLeak(t *testing.T, p *Pool, base int) {
+	t.Helper()
+
+	if !waitFor(5*time.Second, func() bool { return p.Running() == 0 }) {
+		t.Errorf("Running should be 0 after Release, got %d", p.Running())
+	}
+
+	if !waitFor(5*time.Second, func() bool { return runtime.NumGoroutine() <= base }) {
+		t.Errorf("goroutine leak: %d before, %d after Release", base, runtime.NumGoroutine())
+	}
+}
+
+func TestNewPool(t *testing.T) {
+	for _, size := range []int{-1, 0} {
+		if _, err := NewPool(size); err != ErrInvalidPoolSize {
+			t.Errorf("NewPool(%d) should return ErrInvalidPoolSize, got %v", size, err)
+		}
+	}
+
+	p, err := NewPool(10)
+	if err != nil {
+		t.Fatalf("NewPool failed: %v", err)
+	}
+	defer p.Release()
+
+	if p.Cap() != 10 {
+		t.Errorf("Cap should be 10, got %d", p.Cap())
+	}
+
+	if p.Running() != 0 {
+		t.Errorf("Running should be 0 before any Exec, got %d", p.Running())
+	}
+}
+
+func TestExec(t *testing.T) {
+	base := runtime.NumGoroutine()
+
+	p, _ := NewPool(10)
+
+	var (
+		wg sync.WaitGroup
+		n  int64
+	)
+	for i := 0; i < 1000; i++ {
+		wg.Add(1)
+		if err := p.Exec(func() {
+			defer wg.Done()
+			atomic.AddInt64(&n, 1)
+		}); err != nil {
+			t.Fatalf("Exec failed: %v", err)
+		}
+	}
+	wg.Wait()
+
+	if n != 1000 {
+		t.Errorf("1000 tasks should be executed, got %d", n)
+	}
+
+	p.Release()
+	checkNoLeak(t, p, base)
+}
+
+func TestConcurrentExec(t *testing.T) {
+	base := runtime.NumGoroutine()
+
+	p, _ := NewPool(8)
+
+	var (
+		wg   sync.WaitGroup
+		done int64
+	)
+	for g := 0; g < 50; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := 0; i < 200; i++ {
+				var task sync.WaitGroup
+				task.Add(1)
+				if err := p.Exec(func() {
+					defer task.Done()
+					atomic.AddInt64(&done, 1)
+				}); err != nil {
+					t.Errorf("Exec failed: %v", err)
+					return
+				}
+				task.Wait()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if done != 50*200 {
+		t.Errorf("%d tasks should be executed, got %d", 50*200, done)
+	}
+
+	p.Release()
+	checkNoLeak(t, p, base)
+}
+
+func TestCapacity(t *testing.T) {
+	const size = 4
+
+	p, _ := NewPool(size)
+	defer p.Release()
+
+	var (
+		wg       sync.WaitGroup
+		current  int64
+		maxAlive int64
+	)
+	for i := 0; i < 200; i++ {
+		wg.Add(1)
+		go func() {
+			p.Exec(func() {
+				defer wg.Done()
+
+				n := atomic.AddInt64(&current, 1)
+				for {
+					m := atomic.LoadInt64(&maxAlive)
+					if n <= m || atomic.CompareAndSwapInt64(&maxAlive, m, n) {
+						break
+					}
+				}
+				time.Sleep(100 * time.Microsecond)
+				atomic.AddInt64(&current, -1)
+			})
+		}()
+	}
+	wg.Wait()
+
+	if maxAlive > size {
+		t.Errorf("at most %d tasks should run at once, got %d", size, maxAlive)
+	}
+
+	if p.Running() > size {
+		t.Errorf("at most %d workers should be running, got %d", size, p.Running())
+	}
+}
+
+func TestExecBlocksWhenFull(t *testing.T) {
+	p, _ := NewPool(1)
+	defer p.Release()
+
+	block := make(chan struct{})
+	p.Exec(func() { <-block })
+
+	started := make(chan struct{})
+	go func() {
+		p.Exec(func() { close(started) })
+	}()
+
+	select {
+	case <-started:
+		t.Fatal("Exec should wait for an idle worker when the pool is full")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(block)
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("waiting Exec should run once a worker is reverted")
+	}
+}
+
+func TestPanicTask(t *testing.T) {
+	base := runtime.NumGoroutine()
+
+	p, _ := NewPool(2)
+
+	var (
+		wg sync.WaitGroup
+		ok int64
+	)
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		i := i
+		p.Exec(func() {
+			defer wg.Done()
+
+			if i%2 == 0 {
+				panic("task panic")
+			}
+			atomic.AddInt64(&ok, 1)
+		})
+	}
+	wg.Wait()
+
+	if ok != 50 {
+		t.Errorf("50 tasks should be executed, got %d", ok)
+	}
+
+	if p.Running() > p.Cap() {
+		t.Errorf("at most %d workers should be running, got %d", p.Cap(), p.Running())
+	}
+
+	p.Release()
+	checkNoLeak(t, p, base)
+}
+
+func TestRelease(t *testing.T) {
+	base := runtime.NumGoroutine()
+
+	p, _ := NewPool(4)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		p.Exec(func() { wg.Done() })
+	}
+	wg.Wait()
+
+	if err := p.Release(); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+
+	// Release more than once is harmless.
+	if err := p.Release(); err != nil {
+		t.Fatalf("second Release failed: %v", err)
+	}
+
+	if err := p.Exec(func() {}); err != ErrPoolClosed {
+		t.Errorf("Exec after Release should return ErrPoolClosed, got %v", err)
+	}
+
+	checkNoLeak(t, p, base)
+}
+
+func TestReleaseWakesWaitingExec(t *testing.T) {
+	base := runtime.NumGoroutine()
+
+	p, _ := NewPool(1)
+
+	block := make(chan struct{})
+	p.Exec(func() { <-block })
+
+	errc := make(chan error)
+	go func() {
+		errc <- p.Exec(func() {})
+	}()
+
+	// Give Exec the time to start waiting for an idle worker.
+	time.Sleep(20 * time.Millisecond)
+	p.Release()
+
+	select {
+	case err := <-errc:
+		if err != ErrPoolClosed {
+			t.Errorf("waiting Exec should return ErrPoolClosed, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Release should wake up the waiting Exec")
+	}
+
+	// The busy worker exits once its task is done.
+	close(block)
+	checkNoLeak(t, p, base)
+}
+
+func TestConcurrentExecRelease(t *testing.T) {
+	base := runtime.NumGoroutine()
+
+	for round := 0; round < 50; round++ {
+		p, _ := NewPool(4)
+
+		var wg sync.WaitGroup
+		for g := 0; g < 8; g++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				for {
+					if err := p.Exec(func() { runtime.Gosched() }); err != nil {
+						if err != ErrPoolClosed {
+							t.Errorf("Exec should only fail with ErrPoolClosed, got %v", err)
+						}
+						return
+					}
+				}
+			}()
+		}
+
+		time.Sleep(time.Millisecond)
+		p.Release()
+		wg.Wait()
+
+		if !waitFor(5*time.Second, func() bool { return p.Running() == 0 }) {
+			t.Fatalf("round %d: Running should be 0 after Release, got %d", round, p.Running())
+		}
+	}
+
+	if !waitFor(5*time.Second, func() bool { return runtime.NumGoroutine() <= base }) {
+		t.Errorf("goroutine leak: %d before, %d after Release", base, runtime.NumGoroutine())
+	}
+}
+
+// condStack is the idle-worker store used before the channel based one,
+// a slice guarded by a mutex with a sync.Cond to wait for idle workers.
+// It is kept here for benchmark comparisons only.
+type condStack struct {
+	lock    sync.Mutex
+	cond    *sync.Cond
+	workers []*WorkerManager
+}
+
+func newCondStack(size int) *condStack {
+	s := &condStack{}
+	s.cond = sync.NewCond(&s.lock)
+	for i := 0; i < size; i++ {
+		s.workers = append(s.workers, &WorkerManager{})
+	}
+	return s
+}
+
+func (s *condStack) retrieve() *WorkerManager {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for len(s.workers) == 0 {
+		s.cond.Wait()
+	}
+
+	n := len(s.workers) - 1
+	w := s.workers[n]
+	s.workers[n] = nil
+	s.workers = s.workers[:n]
+	return w
+}
+
+func (s *condStack) revert(w *WorkerManager) {
+	s.lock.Lock()
+	s.workers = append(s.workers, w)
+	s.cond.Signal()
+	s.lock.Unlock()
+}
+
+func BenchmarkCondStackRetrieveRevert(b *testing.B) {
+	s := newCondStack(benchPoolSize)
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			s.revert(s.retrieve())
+		}
+	})
+}
+
+func BenchmarkPoolRetrieveRevert(b *testing.B) {
+	p, _ := NewPool(benchPoolSize)
+	defer p.Release()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			p.revertWorker(p.retrieveWorker())
+		}
+	})
+}
+
+func BenchmarkPoolExec(b *testing.B) {
+	p, _ := NewPool(benchPoolSize)
+	defer p.Release()
+
+	var wg sync.WaitGroup
+	task := func() {
+		wg.Done()
+	}
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			wg.Add(1)
+			p.Exec(task)
+		}
+	})
+	wg.Wait()
+}
@@ -19,40 +19,43 @@ type WorkerManager struct {
 	Q chan os.Signal
 }
 
-// start is the start a thread.
+// start is the hand over a task to the worker.
 func (w *WorkerManager) start(task func()) {
-	go func() {
-		w.run()
-	}()
-
 	w.task <- task
 }
 
-// run is the execute the task.
+// run is the start a thread to execute the tasks, the thread lives
+// until the worker is stopped by the pool.
 func (w *WorkerManager) run() {
-	w.pool.incRunning()
 	go func() {
-		defer func() {
-			if p := recover(); p != nil {
-				w.pool.decRunning()
-				log.Printf("Worker exits from a panic: %v", p)
-			}
-		}()
+		defer w.pool.decRunning()
 
 		for f := range w.task {
 			if f == nil {
-				w.pool.decRunning()
 				return
 			}
 
-			f()
+			w.exec(f)
 
 			// Revert worker to pool
-			w.pool.revertWorker(w)
+			if !w.pool.revertWorker(w) {
+				return
+			}
 		}
 	}()
 }
 
+// exec is the execute the task, a panicking task does not kill the worker.
+func (w *WorkerManager) exec(f func()) {
+	defer func() {
+		if p := recover(); p != nil {
+			log.Printf("Worker recovers from a panic: %v", p)
+		}
+	}()
+
+	f()
+}
+
 func (w *WorkerManager) MakeRecvSignal() os.Signal {
 	w.MakeSignal()
 
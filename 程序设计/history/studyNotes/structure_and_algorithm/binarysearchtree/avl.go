@@ -0,0 +1,98 @@
+package binarysearchtree
+
+import "cmp"
+
+// NewAVLTree returns a self-balancing(AVL) tree, it offers the same API as
+// Tree but keeps the height within O(log n) whatever the insertion order
+func NewAVLTree[K cmp.Ordered, V any]() *Tree[K, V] {
+	return &Tree[K, V]{balanced: true}
+}
+
+// internal function to restore the node attributes after its children
+// changed, it returns the new root of the subtree
+func (t *Tree[K, V]) fix(n *Node[K, V]) *Node[K, V] {
+	update(n)
+	if t.balanced {
+		n = balance(n)
+	}
+
+	// Rotations only change the new root and its children.
+	if t.augment != nil {
+		if n.Left != nil {
+			t.augment(n.Left)
+		}
+		if n.Right != nil {
+			t.augment(n.Right)
+		}
+		t.augment(n)
+	}
+	return n
+}
+
+// height returns the height of the subtree, 0 for an empty one
+func height[K cmp.Ordered, V any](n *Node[K, V]) int {
+	if n == nil {
+		return 0
+	}
+	return n.height
+}
+
+// update recomputes the height and the size of the node from its children
+func update[K cmp.Ordered, V any](n *Node[K, V]) {
+	n.height = 1 + max(height(n.Left), height(n.Right))
+	n.size = 1 + size(n.Left) + size(n.Right)
+}
+
+// balance rotates the subtree if the heights of the children differ by
+// more than one, it returns the new root of the subtree
+func balance[K cmp.Ordered, V any](n *Node[K, V]) *Node[K, V] {
+	switch bf := height(n.Left) - height(n.Right); {
+	case bf > 1:
+		// Left-Right case
+		if height(n.Left.Left) < height(n.Left.Right) {
+			n.Left = rotateLeft(n.Left)
+		}
+		return rotateRight(n)
+	case bf < -1:
+		// Right-Left case
+		if height(n.Right.Right) < height(n.Right.Left) {
+			n.Right = rotateRight(n.Right)
+		}
+		return rotateLeft(n)
+	}
+	return n
+}
+
+// rotateRight rotates the subtree to the right
+//
+//	    n            l
+//	   / \          / \
+//	  l   c  ==>   a   n
+//	 / \              / \
+//	a   b            b   c
+func rotateRight[K cmp.Ordered, V any](n *Node[K, V]) *Node[K, V] {
+	l := n.Left
+	n.Left = l.Right
+	l.Right = n
+
+	update(n)
+	update(l)
+	return l
+}
+
+// rotateLeft rotates the subtree to the left
+//
+//	  n                r
+//	 / \              / \
+//	a   r    ==>     n   c
+//	   / \          / \
+//	  b   c        a   b
+func rotateLeft[K cmp.Ordered, V any](n *Node[K, V]) *Node[K, V] {
+	r := n.Right
+	n.Right = r.Left
+	r.Left = n
+
+	update(n)
+	update(r)
+	return r
+}
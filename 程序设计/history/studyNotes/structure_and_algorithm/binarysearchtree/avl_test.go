@@ -0,0 +1,117 @@
+package binarysearchtree
+
+import (
+	"math"
+	"testing"
+)
+
+// checkAVL returns the height of the subtree and fails the test if the
+// subtree is not height balanced
+func checkAVL[V any](t *testing.T, n *Node[int, V]) int {
+	t.Helper()
+
+	if n == nil {
+		return 0
+	}
+
+	l, r := checkAVL(t, n.Left), checkAVL(t, n.Right)
+	if l-r > 1 || r-l > 1 {
+		t.Fatalf("node %d is not balanced, left height %d, right height %d", n.Key, l, r)
+	}
+
+	h := 1 + max(l, r)
+	if n.height != h {
+		t.Fatalf("node %d height should be %d, got %d", n.Key, h, n.height)
+	}
+	return h
+}
+
+func TestAVLTreeSortedInsert(t *testing.T) {
+	const n = 1000
+
+	avl := NewAVLTree[int, int]()
+	for i := 0; i < n; i++ {
+		avl.Insert(i, i)
+	}
+
+	h := checkAVL(t, avl.Root)
+	if limit := int(1.44*math.Log2(n+2)) + 1; h > limit {
+		t.Errorf("height should be at most %d, got %d", limit, h)
+	}
+
+	prev := -1
+	avl.TraverseAllNodes(func(v int) {
+		if v != prev+1 {
+			t.Fatalf("traversal order incorrect, got %d after %d", v, prev)
+		}
+		prev = v
+	})
+
+	if avl.Len() != n || avl.Min() != 0 || avl.Max() != n-1 {
+		t.Errorf("Len, Min, Max should be %d, 0, %d, got %d, %d, %d", n, n-1, avl.Len(), avl.Min(), avl.Max())
+	}
+}
+
+func TestAVLTreeRemove(t *testing.T) {
+	avl := NewAVLTree[int, int]()
+	for i := 0; i < 500; i++ {
+		avl.Put(i, i)
+	}
+
+	for i := 0; i < 500; i += 2 {
+		if !avl.Delete(i) {
+			t.Fatalf("Delete(%d) should succeed", i)
+		}
+		checkAVL(t, avl.Root)
+	}
+
+	for i := 0; i < 500; i++ {
+		if avl.Search(i) != (i%2 == 1) {
+			t.Errorf("Search(%d) should be %v", i, i%2 == 1)
+		}
+	}
+
+	for i := 1; i < 500; i += 2 {
+		avl.Remove(i)
+		checkAVL(t, avl.Root)
+	}
+
+	if avl.Root != nil || avl.Len() != 0 {
+		t.Errorf("tree should be empty, got Len %d", avl.Len())
+	}
+}
+
+func benchmarkSortedInsert(b *testing.B, newTree func() *Tree[int, int], n int) {
+	for i := 0; i < b.N; i++ {
+		t := newTree()
+		for k := 0; k < n; k++ {
+			t.Insert(k, k)
+		}
+	}
+}
+
+func benchmarkSortedSearch(b *testing.B, newTree func() *Tree[int, int], n int) {
+	t := newTree()
+	for k := 0; k < n; k++ {
+		t.Insert(k, k)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		t.Search(i % n)
+	}
+}
+
+func newTree() *Tree[int, int] {
+	return &Tree[int, int]{}
+}
+
+func BenchmarkSortedInsert(b *testing.B) {
+	b.Run("Tree", func(b *testing.B) { benchmarkSortedInsert(b, newTree, 2000) })
+	b.Run("AVLTree", func(b *testing.B) { benchmarkSortedInsert(b, NewAVLTree[int, int], 2000) })
+}
+
+func BenchmarkSortedSearch(b *testing.B) {
+	b.Run("Tree", func(b *testing.B) { benchmarkSortedSearch(b, newTree, 2000) })
+	b.Run("AVLTree", func(b *testing.B) { benchmarkSortedSearch(b, NewAVLTree[int, int], 2000) })
+}
@@ -1,63 +1,145 @@
 package binarysearchtree
 
-import "sync"
+import (
+	"cmp"
+	"sync"
+	"time"
+)
 
 // Node a single node that composes the tree
-type Node struct {
-	Key   int         `json:"key"`
-	Value interface{} `json:"value"`
-	Left  *Node       `json:"left"`
-	Right *Node       `json:"right"`
+type Node[K cmp.Ordered, V any] struct {
+	Key   K           `json:"key"`
+	Value V           `json:"value"`
+	Left  *Node[K, V] `json:"left"`
+	Right *Node[K, V] `json:"right"`
+
+	// height of the subtree rooted at this node
+	height int
+
+	// number of nodes in the subtree rooted at this node
+	size int
+
+	// expiry time in unix nanoseconds, 0 if the node never expires
+	expires int64
+}
+
+// internal function to create a leaf node
+func newNode[K cmp.Ordered, V any](key K, value V) *Node[K, V] {
+	return &Node[K, V]{Key: key, Value: value, height: 1, size: 1}
 }
 
-// Tree the binary search tree(Thread safe)
-type Tree struct {
-	Root *Node
+// DuplicatePolicy decides what Insert does with a key already in the tree
+type DuplicatePolicy int
+
+const (
+	// DuplicateReplace replaces the value of the existing key(default)
+	DuplicateReplace DuplicatePolicy = iota
+
+	// DuplicateReject keeps the existing value and drops the new one
+	DuplicateReject
+
+	// DuplicateMultiset stores the key once more, right to the existing one
+	DuplicateMultiset
+)
 
-	Lock sync.RWMutex
+// Tree the binary search tree(Thread safe), it can be used as a typed
+// ordered map through Get, Put, Delete and Len
+type Tree[K cmp.Ordered, V any] struct {
+	Root *Node[K, V]
+
+	// read only operations take the read lock, so they run in parallel
+	lock sync.RWMutex
+
+	// balanced keeps the tree height balanced(AVL), see NewAVLTree
+	balanced bool
+
+	// what Insert does with duplicate keys
+	duplicates DuplicatePolicy
+
+	// augment recomputes extra data kept in the node values from the
+	// children, called whenever a node is fixed, see IntervalTree
+	augment func(n *Node[K, V])
+
+	// onExpire is called with the entries removed once expired, see
+	// OnExpire
+	onExpire func(key K, value V)
+
+	// clock returns the current time, time.Now if nil
+	clock func() time.Time
+
+	// watchers receive the changes of the tree, see Watch
+	watchers []*Watcher[K, V]
 }
 
-// Insert inserts the value t in the tree
-func (t *Tree) Insert(key int, value interface{}) {
-	t.Lock.Lock()
-	defer t.Lock.Unlock()
+// SetDuplicatePolicy sets what Insert does with a key already in the tree
+func (t *Tree[K, V]) SetDuplicatePolicy(p DuplicatePolicy) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
 
-	n := &Node{key, value, nil, nil}
+	t.duplicates = p
+}
 
-	if t.Root == nil {
-		t.Root = n
-	} else {
-		insertNode(t.Root, n)
+// Insert inserts the value t in the tree according to the duplicate policy,
+// it returns false if the value was rejected. An expired entry with the same
+// key is removed first, as if it was missing.
+func (t *Tree[K, V]) Insert(key K, value V) bool {
+	t.lock.Lock()
+	expired := t.unlinkExpired(key)
+	ok := t.insert(key, value)
+	f := t.onExpire
+	t.lock.Unlock()
+
+	notifyExpired(f, expired)
+	return ok
+}
+
+// internal function to insert the value according to the duplicate policy,
+// the caller must hold the lock
+func (t *Tree[K, V]) insert(key K, value V) bool {
+	if t.duplicates != DuplicateMultiset {
+		if n := lookup(t.Root, key); n != nil {
+			if t.duplicates == DuplicateReject {
+				return false
+			}
+
+			t.notify(Event[K, V]{Type: EventUpdate, Key: key, Value: value, OldValue: n.Value})
+			n.Value = value
+			n.expires = 0
+			return true
+		}
 	}
+
+	t.Root = t.insertNode(t.Root, newNode(key, value))
+	t.notify(Event[K, V]{Type: EventInsert, Key: key, Value: value})
+	return true
 }
 
-// internal function to find the correct place for a node in a tree
-func insertNode(node, newNode *Node) {
+// internal function to find the correct place for a node in a tree, it
+// returns the new root of the subtree
+func (t *Tree[K, V]) insertNode(node, newNode *Node[K, V]) *Node[K, V] {
+	if node == nil {
+		return t.fix(newNode)
+	}
+
 	if newNode.Key < node.Key {
-		if node.Left == nil {
-			node.Left = newNode
-		} else {
-			insertNode(node.Left, newNode)
-		}
+		node.Left = t.insertNode(node.Left, newNode)
 	} else {
-		if node.Right == nil {
-			node.Right = newNode
-		} else {
-			insertNode(node.Right, newNode)
-		}
+		node.Right = t.insertNode(node.Right, newNode)
 	}
+	return t.fix(node)
 }
 
-// TraverseAllNodes visits all nodes with in-order traversing
-func (t *Tree) TraverseAllNodes(f func(interface{})) {
-	t.Lock.Lock()
-	defer t.Lock.Unlock()
+// TraverseAllNodes visits all nodes with in-order traversing, including
+// the expired ones
+func (t *Tree[K, V]) TraverseAllNodes(f func(V)) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
 
 	traverseAllNodes(t.Root, f)
 }
 
 // internal recursive function to traverse in order
-func traverseAllNodes(n *Node, f func(interface{})) {
+func traverseAllNodes[K cmp.Ordered, V any](n *Node[K, V], f func(V)) {
 	if n != nil {
 		traverseAllNodes(n.Left, f)
 		f(n.Value)
@@ -65,16 +147,17 @@ func traverseAllNodes(n *Node, f func(interface{})) {
 	}
 }
 
-// PreOrderTraverse visits all nodes with pre-order traversin
-func (t *Tree) PreOrderTraverse(f func(interface{})) {
-	t.Lock.Lock()
-	defer t.Lock.Unlock()
+// PreOrderTraverse visits all nodes with pre-order traversin, including
+// the expired ones
+func (t *Tree[K, V]) PreOrderTraverse(f func(V)) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
 
 	preOrderTraverse(t.Root, f)
 }
 
 // internal recursive function to traverse pre order
-func preOrderTraverse(n *Node, f func(interface{})) {
+func preOrderTraverse[K cmp.Ordered, V any](n *Node[K, V], f func(V)) {
 	if n != nil {
 		f(n.Value)
 		preOrderTraverse(n.Left, f)
@@ -82,16 +165,17 @@ func preOrderTraverse(n *Node, f func(interface{})) {
 	}
 }
 
-// PostOrderTraverse  visits all nodes with post-order traversing
-func (t *Tree) PostOrderTraverse(f func(interface{})) {
-	t.Lock.Lock()
-	defer t.Lock.Unlock()
+// PostOrderTraverse  visits all nodes with post-order traversing,
+// including the expired ones
+func (t *Tree[K, V]) PostOrderTraverse(f func(V)) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
 
 	postOrderTraverse(t.Root, f)
 }
 
 // internal recursive function to traverse post order
-func postOrderTraverse(n *Node, f func(interface{})) {
+func postOrderTraverse[K cmp.Ordered, V any](n *Node[K, V], f func(V)) {
 	if n != nil {
 		postOrderTraverse(n.Left, f)
 		postOrderTraverse(n.Right, f)
@@ -99,117 +183,187 @@ func postOrderTraverse(n *Node, f func(interface{})) {
 	}
 }
 
-// Min returns the value with min value stored in the tree
-func (t *Tree) Min() interface{} {
-	t.Lock.Lock()
-	defer t.Lock.Unlock()
+// Min returns the value with min value stored in the tree, skipping the
+// expired entries
+func (t *Tree[K, V]) Min() V {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
 
-	n := t.Root
-	if n == nil {
-		return nil
+	if n := t.firstLive(ascend[K, V]); n != nil {
+		return n.Value
 	}
 
-	for {
-		if n.Left == nil {
-			return n.Value
-		}
-		n = n.Left
-	}
-	// return n
+	var zero V
+	return zero
 }
 
-// Max returns the value with max value stored in the tree
-func (t *Tree) Max() interface{} {
-	t.Lock.Lock()
-	defer t.Lock.Unlock()
+// Max returns the value with max value stored in the tree, skipping the
+// expired entries
+func (t *Tree[K, V]) Max() V {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
 
-	n := t.Root
-	if n == nil {
-		return nil
+	if n := t.firstLive(descend[K, V]); n != nil {
+		return n.Value
 	}
 
-	for {
-		if n.Right == nil {
-			return n.Value
-		}
-		n = n.Right
-	}
+	var zero V
+	return zero
 }
 
-// Search returns true if the value t exists in the tree
-func (t *Tree) Search(key int) bool {
-	t.Lock.Lock()
-	defer t.Lock.Unlock()
-
-	return search(t.Root, key)
+// Search returns true if the key exists in the tree and has not expired
+func (t *Tree[K, V]) Search(key K) bool {
+	_, ok := t.Get(key)
+	return ok
 }
 
-// internal recursive function to search an value in the tree
-func search(n *Node, key int) bool {
-	if n == nil {
-		return false
+// Remove removes the value with key `key` from the tree, it returns the
+// removed value and false if the key does not exist. An expired entry is
+// removed as well, but reported as missing.
+func (t *Tree[K, V]) Remove(key K) (V, bool) {
+	t.lock.Lock()
+	expired := t.unlinkExpired(key)
+
+	var removed *Node[K, V]
+	t.Root, removed = t.remove(t.Root, key)
+	if removed != nil {
+		t.notify(Event[K, V]{Type: EventRemove, Key: key, Value: removed.Value})
 	}
 
-	if key < n.Key {
-		return search(n.Left, key)
-	}
+	f := t.onExpire
+	t.lock.Unlock()
 
-	if key > n.Key {
-		return search(n.Right, key)
+	notifyExpired(f, expired)
+	if removed == nil {
+		var zero V
+		return zero, false
 	}
-
-	return true
+	return removed.Value, true
 }
 
-// Remove removes the value with key `key` from the tree
-func (t *Tree) Remove(key int) {
-	t.Lock.Lock()
-	defer t.Lock.Unlock()
-
-	remove(t.Root, key)
-}
-
-// internal recursive function to remove an value
-func remove(node *Node, key int) *Node {
+// internal recursive function to remove an value, it returns the new root
+// of the subtree and the removed node(nil if the key was not found)
+func (t *Tree[K, V]) remove(node *Node[K, V], key K) (*Node[K, V], *Node[K, V]) {
 	if node == nil {
-		return nil
+		return nil, nil
 	}
 
+	var removed *Node[K, V]
 	if key < node.Key {
-		node.Left = remove(node.Left, key)
-		return node
+		node.Left, removed = t.remove(node.Left, key)
+		return t.fix(node), removed
 	}
 
 	if key > node.Key {
-		node.Right = remove(node.Right, key)
-		return node
+		node.Right, removed = t.remove(node.Right, key)
+		return t.fix(node), removed
 	}
 
-	if node.Left == nil && node.Right == nil {
-		node = nil
-		return nil
+	left, right := node.Left, node.Right
+	node.Left, node.Right = nil, nil
+
+	if left == nil {
+		return right, node
 	}
 
+	if right == nil {
+		return left, node
+	}
+
+	// Replace the node with the smallest node on the right side.
+	right, successor := t.removeMin(right)
+	successor.Left, successor.Right = left, right
+	return t.fix(successor), node
+}
+
+// internal recursive function to unlink the smallest node of a subtree, it
+// returns the new root of the subtree and the unlinked node
+func (t *Tree[K, V]) removeMin(node *Node[K, V]) (*Node[K, V], *Node[K, V]) {
 	if node.Left == nil {
-		node = node.Right
-		return node
+		right := node.Right
+		node.Right = nil
+		return right, node
 	}
 
-	if node.Right == nil {
-		node = node.Left
-		return node
+	var min *Node[K, V]
+	node.Left, min = t.removeMin(node.Left)
+	return t.fix(node), min
+}
+
+// Get returns the value stored with key `key`, an expired entry is reported
+// as missing. Get doesn't change the tree, the entry is removed by the next
+// change of the key or by ExpireBefore.
+func (t *Tree[K, V]) Get(key K) (V, bool) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	if n := t.lookupLive(t.Root, key); n != nil {
+		return n.Value, true
 	}
 
-	leftMostRightSide := node.Right
-	for {
-		// Find smallest value on the right side.
-		if leftMostRightSide != nil && leftMostRightSide.Left != nil {
-			leftMostRightSide = leftMostRightSide.Left
-		} else {
-			break
+	var zero V
+	return zero, false
+}
+
+// internal function to find the node with key `key`, nil if not found
+func lookup[K cmp.Ordered, V any](n *Node[K, V], key K) *Node[K, V] {
+	for n != nil {
+		switch {
+		case key < n.Key:
+			n = n.Left
+		case key > n.Key:
+			n = n.Right
+		default:
+			return n
 		}
 	}
-	node.Key, node.Value = leftMostRightSide.Key, leftMostRightSide.Value
-	node.Right = remove(node.Right, node.Key)
-	return node
+	return nil
+}
+
+// Put stores the value with key `key`, replacing the old value if the key
+// already exists in the tree. The entry never expires.
+func (t *Tree[K, V]) Put(key K, value V) {
+	t.putExpiring(key, value, 0)
+}
+
+// internal recursive function to put an value expiring at `expires`(0 for
+// never), it returns the new root of the subtree
+func (t *Tree[K, V]) put(node *Node[K, V], key K, value V, expires int64) *Node[K, V] {
+	if node == nil {
+		n := newNode(key, value)
+		n.expires = expires
+		t.notify(Event[K, V]{Type: EventInsert, Key: key, Value: value})
+		return t.fix(n)
+	}
+
+	switch {
+	case key < node.Key:
+		node.Left = t.put(node.Left, key, value, expires)
+	case key > node.Key:
+		node.Right = t.put(node.Right, key, value, expires)
+	default:
+		// The node is fixed anyway, an augmented value may depend on it.
+		t.notify(Event[K, V]{Type: EventUpdate, Key: key, Value: value, OldValue: node.Value})
+		node.Value = value
+		node.expires = expires
+	}
+	return t.fix(node)
+}
+
+// Delete removes the key `key` from the tree, it returns false if the key
+// does not exist
+func (t *Tree[K, V]) Delete(key K) bool {
+	_, ok := t.Remove(key)
+	return ok
+}
+
+// Len returns the number of nodes stored in the tree, including the
+// expired ones not removed yet. Len, Rank and Select use the subtree sizes
+// kept in the nodes, they can't skip the expired entries in O(1) or
+// O(log n).
+func (t *Tree[K, V]) Len() int {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return size(t.Root)
 }
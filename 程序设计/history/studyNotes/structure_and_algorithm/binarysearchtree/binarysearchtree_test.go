@@ -2,12 +2,14 @@ package binarysearchtree
 
 import (
 	"fmt"
+	"sort"
+	"sync"
 	"testing"
 )
 
-var bst Tree
+var bst Tree[int, interface{}]
 
-func FillTree(bst *Tree) {
+func FillTree(bst *Tree[int, interface{}]) {
 	bst.Insert(8, "8")
 	bst.Insert(10, "10")
 	bst.Insert(6, "6")
@@ -161,3 +163,252 @@ func TestRemove(t *testing.T) {
 		t.Errorf("min should be 2")
 	}
 }
+
+func TestGetPutDelete(t *testing.T) {
+	var m Tree[string, int]
+
+	m.Put("b", 2)
+	m.Put("a", 1)
+	m.Put("c", 3)
+	m.Put("b", 20)
+
+	if m.Len() != 3 {
+		t.Errorf("Len should be 3, got %d", m.Len())
+	}
+
+	if v, ok := m.Get("b"); !ok || v != 20 {
+		t.Errorf("Get(b) should be 20, got %d %v", v, ok)
+	}
+
+	if _, ok := m.Get("d"); ok {
+		t.Errorf("Get(d) should not be found")
+	}
+
+	if !m.Delete("b") || m.Delete("b") {
+		t.Errorf("Delete(b) should only succeed once")
+	}
+
+	if !m.Delete("a") || !m.Delete("c") {
+		t.Errorf("Delete(a) and Delete(c) should succeed")
+	}
+
+	if m.Len() != 0 || m.Root != nil {
+		t.Errorf("tree should be empty, got Len %d", m.Len())
+	}
+}
+
+// inOrderKeys returns the keys of the tree with in-order traversing
+func inOrderKeys(n *Node[int, string], keys []int) []int {
+	if n == nil {
+		return keys
+	}
+
+	keys = inOrderKeys(n.Left, keys)
+	keys = append(keys, n.Key)
+	return inOrderKeys(n.Right, keys)
+}
+
+func TestRemoveShapes(t *testing.T) {
+	//        8
+	//      /   \
+	//     4     12
+	//    / \   /  \
+	//   2   6 10   14
+	//  /     \    /  \
+	// 1       7  13  15
+	build := []int{8, 4, 12, 2, 6, 10, 14, 1, 7, 13, 15}
+
+	tests := []struct {
+		name string
+		keys []int
+		key  int
+		root int
+	}{
+		{"leaf", build, 10, 8},
+		{"only left child", build, 2, 8},
+		{"only right child", build, 6, 8},
+		{"two children, successor is right child", build, 4, 8},
+		{"two children, successor is deeper", build, 12, 8},
+		{"root with two children", build, 8, 10},
+		{"root leaf", []int{8}, 8, 0},
+		{"root with only left child", []int{8, 4, 2}, 8, 4},
+		{"root with only right child", []int{8, 12, 14}, 8, 12},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tr Tree[int, string]
+			for _, k := range tt.keys {
+				tr.Insert(k, fmt.Sprint(k))
+			}
+
+			v, ok := tr.Remove(tt.key)
+			if !ok || v != fmt.Sprint(tt.key) {
+				t.Fatalf("Remove(%d) should return %q, true, got %q, %v", tt.key, fmt.Sprint(tt.key), v, ok)
+			}
+
+			if tr.Len() != len(tt.keys)-1 {
+				t.Errorf("Len should be %d, got %d", len(tt.keys)-1, tr.Len())
+			}
+
+			if tt.root == 0 {
+				if tr.Root != nil {
+					t.Errorf("Root should be nil, got %d", tr.Root.Key)
+				}
+			} else if tr.Root == nil || tr.Root.Key != tt.root {
+				t.Errorf("Root should be %d, got %v", tt.root, tr.Root)
+			}
+
+			var want []int
+			for _, k := range tt.keys {
+				if k != tt.key {
+					want = append(want, k)
+				}
+			}
+			sort.Ints(want)
+
+			got := inOrderKeys(tr.Root, nil)
+			if fmt.Sprint(got) != fmt.Sprint(want) {
+				t.Errorf("keys should be %v, got %v", want, got)
+			}
+
+			for _, k := range want {
+				if v, ok := tr.Get(k); !ok || v != fmt.Sprint(k) {
+					t.Errorf("Get(%d) should return %q, got %q, %v", k, fmt.Sprint(k), v, ok)
+				}
+			}
+		})
+	}
+}
+
+func TestRemoveMissing(t *testing.T) {
+	var tr Tree[int, string]
+
+	if _, ok := tr.Remove(1); ok {
+		t.Errorf("Remove on an empty tree should return false")
+	}
+
+	tr.Insert(1, "1")
+	tr.Insert(2, "2")
+
+	if v, ok := tr.Remove(3); ok || v != "" {
+		t.Errorf("Remove(3) should return \"\", false, got %q, %v", v, ok)
+	}
+
+	if tr.Len() != 2 {
+		t.Errorf("Len should be 2, got %d", tr.Len())
+	}
+}
+
+func TestRemoveDuplicates(t *testing.T) {
+	var tr Tree[int, string]
+	tr.SetDuplicatePolicy(DuplicateMultiset)
+	for _, v := range []string{"a", "b", "c"} {
+		tr.Insert(5, v)
+	}
+	tr.Insert(3, "3")
+	tr.Insert(7, "7")
+
+	got := map[string]bool{}
+	for i := 0; i < 3; i++ {
+		v, ok := tr.Remove(5)
+		if !ok || got[v] {
+			t.Fatalf("Remove(5) should return each duplicate once, got %q, %v", v, ok)
+		}
+		got[v] = true
+	}
+
+	if tr.Search(5) || tr.Len() != 2 {
+		t.Errorf("all the 5 keys should be removed, Len %d", tr.Len())
+	}
+}
+
+func TestDuplicatePolicy(t *testing.T) {
+	tests := []struct {
+		policy DuplicatePolicy
+		stored bool
+		value  string
+		len    int
+	}{
+		{DuplicateReplace, true, "new", 1},
+		{DuplicateReject, false, "old", 1},
+		{DuplicateMultiset, true, "old", 2},
+	}
+
+	for _, tt := range tests {
+		var tr Tree[int, string]
+		tr.SetDuplicatePolicy(tt.policy)
+
+		tr.Insert(1, "old")
+		if stored := tr.Insert(1, "new"); stored != tt.stored {
+			t.Errorf("policy %d: Insert should return %v, got %v", tt.policy, tt.stored, stored)
+		}
+
+		if v, ok := tr.Get(1); !ok || v != tt.value {
+			t.Errorf("policy %d: Get(1) should return %q, got %q, %v", tt.policy, tt.value, v, ok)
+		}
+
+		if tr.Len() != tt.len {
+			t.Errorf("policy %d: Len should be %d, got %d", tt.policy, tt.len, tr.Len())
+		}
+
+		// Put always replaces whatever the policy.
+		tr.Put(1, "put")
+		if v, _ := tr.Get(1); v != "put" {
+			t.Errorf("policy %d: Get(1) should return \"put\" after Put, got %q", tt.policy, v)
+		}
+	}
+}
+
+// benchmarkParallelRead runs read on all the procs against an AVL tree of
+// n keys, with 1 write every writeEvery reads(0 means no write)
+func benchmarkParallelRead(b *testing.B, n, writeEvery int, read func(t *Tree[int, int], key int)) {
+	tr := NewAVLTree[int, int]()
+	for k := 0; k < n; k++ {
+		tr.Put(k, k)
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			i++
+			if writeEvery > 0 && i%writeEvery == 0 {
+				tr.Put(i%n, i)
+				continue
+			}
+			read(tr, i%n)
+		}
+	})
+}
+
+func BenchmarkParallelGet(b *testing.B) {
+	var m sync.Mutex
+
+	reads := []struct {
+		name string
+		read func(t *Tree[int, int], key int)
+	}{
+		{"RLock", func(t *Tree[int, int], key int) { t.Get(key) }},
+		// Serialise the reads like an exclusive lock does, for comparison.
+		{"Lock", func(t *Tree[int, int], key int) {
+			m.Lock()
+			t.Get(key)
+			m.Unlock()
+		}},
+	}
+
+	for _, r := range reads {
+		for _, writeEvery := range []int{0, 100} {
+			b.Run(fmt.Sprintf("%s/writeEvery=%d", r.name, writeEvery), func(b *testing.B) {
+				benchmarkParallelRead(b, 10000, writeEvery, r.read)
+			})
+		}
+	}
+}
+
+func BenchmarkParallelRange(b *testing.B) {
+	benchmarkParallelRead(b, 10000, 0, func(t *Tree[int, int], key int) {
+		t.Range(key, key+10, func(int, int) bool { return true })
+	})
+}
@@ -0,0 +1,150 @@
+package binarysearchtree
+
+import "cmp"
+
+// BuildFromSorted returns an AVL tree built in O(n) from keys sorted in
+// strictly ascending order, values[i] is the value of keys[i]. The tree is
+// perfectly balanced, whereas inserting sorted keys one at a time into a
+// Tree degenerates into a linked list.
+func BuildFromSorted[K cmp.Ordered, V any](keys []K, values []V) (*Tree[K, V], error) {
+	if err := checkSorted(keys, values, false); err != nil {
+		return nil, err
+	}
+
+	t := NewAVLTree[K, V]()
+	t.Root = buildFromSorted(keys, values)
+	return t, nil
+}
+
+// Split moves the keys greater than or equal to `key` into a new tree and
+// returns it, the keys less than `key` stay in t. Both trees are rebuilt
+// balanced in O(n) and keep the settings of t.
+func (t *Tree[K, V]) Split(key K) *Tree[K, V] {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	nodes := appendNodes(t.Root, nil)
+
+	// Index of the first key greater than or equal to `key`.
+	i := rankOf(t.Root, key)
+
+	right := &Tree[K, V]{balanced: t.balanced, duplicates: t.duplicates, onExpire: t.onExpire, clock: t.clock}
+	t.notifyNodes(EventRemove, nodes[i:])
+	right.Root = linkSorted(nodes[i:])
+	t.Root = linkSorted(nodes[:i])
+	return right
+}
+
+// Merge inserts all the keys of other into t in O(n+m) according to the
+// duplicate policy of t, other is left unchanged. The tree is rebuilt
+// balanced.
+func (t *Tree[K, V]) Merge(other *Tree[K, V]) {
+	b := other.copyNodes()
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	a := appendNodes(t.Root, nil)
+	m := make([]*Node[K, V], 0, len(a)+len(b))
+
+	// Only the nodes of other change t, their events are sent in order.
+	add := func(n *Node[K, V], other bool) {
+		last := len(m) - 1
+		if last >= 0 && m[last].Key == n.Key && t.duplicates != DuplicateMultiset {
+			if t.duplicates == DuplicateReplace {
+				t.notify(Event[K, V]{Type: EventUpdate, Key: n.Key, Value: n.Value, OldValue: m[last].Value})
+				m[last] = n
+			}
+			return
+		}
+
+		if other {
+			t.notify(Event[K, V]{Type: EventInsert, Key: n.Key, Value: n.Value})
+		}
+		m = append(m, n)
+	}
+
+	// On equal keys the ones of t come first, so that the keys of other
+	// replace them or are rejected.
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		if j == len(b) || (i < len(a) && a[i].Key <= b[j].Key) {
+			add(a[i], false)
+			i++
+		} else {
+			add(b[j], true)
+			j++
+		}
+	}
+
+	t.Root = linkSorted(m)
+}
+
+// internal function to copy the nodes of the tree in ascending order, the
+// copies keep the expiry time
+func (t *Tree[K, V]) copyNodes() []*Node[K, V] {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	nodes := appendNodes(t.Root, make([]*Node[K, V], 0, size(t.Root)))
+	for i, n := range nodes {
+		c := newNode(n.Key, n.Value)
+		c.expires = n.expires
+		nodes[i] = c
+	}
+	return nodes
+}
+
+// internal recursive function to append the nodes of the subtree to dst in
+// ascending order
+func appendNodes[K cmp.Ordered, V any](n *Node[K, V], dst []*Node[K, V]) []*Node[K, V] {
+	if n == nil {
+		return dst
+	}
+
+	dst = appendNodes(n.Left, dst)
+	dst = append(dst, n)
+	return appendNodes(n.Right, dst)
+}
+
+// internal recursive function to link sorted nodes into a perfectly
+// balanced subtree, it returns the root of the subtree
+func linkSorted[K cmp.Ordered, V any](nodes []*Node[K, V]) *Node[K, V] {
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	mid := len(nodes) / 2
+	n := nodes[mid]
+	n.Left = linkSorted(nodes[:mid])
+	n.Right = linkSorted(nodes[mid+1:])
+	update(n)
+	return n
+}
+
+// internal function to collect the keys, values and expiry times of the
+// entries not expired in ascending order, the caller must hold the lock
+func (t *Tree[K, V]) entries() encodedTree[K, V] {
+	nodes := appendNodes(t.Root, make([]*Node[K, V], 0, size(t.Root)))
+	e := encodedTree[K, V]{
+		Keys:   make([]K, 0, len(nodes)),
+		Values: make([]V, 0, len(nodes)),
+	}
+
+	expires := make([]int64, 0, len(nodes))
+	ttl := false
+	for _, n := range nodes {
+		if t.expired(n) {
+			continue
+		}
+		e.Keys = append(e.Keys, n.Key)
+		e.Values = append(e.Values, n.Value)
+		expires = append(expires, n.expires)
+		ttl = ttl || n.expires != 0
+	}
+
+	if ttl {
+		e.Expires = expires
+	}
+	return e
+}
@@ -0,0 +1,133 @@
+package binarysearchtree
+
+import (
+	"fmt"
+	"testing"
+)
+
+// keysOf returns the keys of the tree in ascending order
+func keysOf[V any](t *Tree[int, V]) []int {
+	var keys []int
+	for k := range t.All() {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func TestBuildFromSorted(t *testing.T) {
+	keys := make([]int, 1023)
+	values := make([]string, len(keys))
+	for i := range keys {
+		keys[i], values[i] = i*2, fmt.Sprint(i*2)
+	}
+
+	tr, err := BuildFromSorted(keys, values)
+	if err != nil {
+		t.Fatalf("BuildFromSorted failed: %v", err)
+	}
+
+	// 1023 nodes fill exactly 10 levels.
+	if h := checkAVL(t, tr.Root); h != 10 {
+		t.Errorf("height should be 10, got %d", h)
+	}
+
+	if tr.Len() != 1023 {
+		t.Errorf("Len should be 1023, got %d", tr.Len())
+	}
+
+	if v, ok := tr.Get(500); !ok || v != "500" {
+		t.Errorf("Get(500) should be \"500\", got %q, %v", v, ok)
+	}
+
+	if _, err := BuildFromSorted([]int{1, 3, 2}, []string{"1", "3", "2"}); err != ErrUnsortedKeys {
+		t.Errorf("unsorted keys should return ErrUnsortedKeys, got %v", err)
+	}
+
+	if _, err := BuildFromSorted([]int{1, 2}, []string{"1"}); err != ErrLengthMismatch {
+		t.Errorf("mismatched lengths should return ErrLengthMismatch, got %v", err)
+	}
+
+	if empty, err := BuildFromSorted[int, string](nil, nil); err != nil || empty.Len() != 0 {
+		t.Errorf("empty input should build an empty tree, got %v", err)
+	}
+}
+
+func TestSplit(t *testing.T) {
+	for _, tt := range []struct {
+		key         int
+		left, right string
+	}{
+		{10, "[0 2 4 6 8]", "[10 12 14 16 18]"},
+		{11, "[0 2 4 6 8 10]", "[12 14 16 18]"},
+		{-1, "[]", "[0 2 4 6 8 10 12 14 16 18]"},
+		{100, "[0 2 4 6 8 10 12 14 16 18]", "[]"},
+	} {
+		tr := NewAVLTree[int, int]()
+		for i := 0; i < 10; i++ {
+			tr.Insert(i*2, i*2)
+		}
+
+		right := tr.Split(tt.key)
+		if got := fmt.Sprint(keysOf(tr)); got != tt.left {
+			t.Errorf("Split(%d): left should be %s, got %s", tt.key, tt.left, got)
+		}
+		if got := fmt.Sprint(keysOf(right)); got != tt.right {
+			t.Errorf("Split(%d): right should be %s, got %s", tt.key, tt.right, got)
+		}
+
+		checkAVL(t, tr.Root)
+		checkAVL(t, right.Root)
+
+		// The new tree keeps balancing.
+		for i := 100; i < 200; i++ {
+			right.Insert(i, i)
+		}
+		checkAVL(t, right.Root)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		policy DuplicatePolicy
+		want   string
+	}{
+		{DuplicateReplace, "[1:a 2:b 3:B 4:b 5:a 6:B]"},
+		{DuplicateReject, "[1:a 2:b 3:a 4:b 5:a 6:B]"},
+		{DuplicateMultiset, "[1:a 2:b 3:a 3:B 4:b 5:a 6:B]"},
+	}
+
+	for _, tt := range tests {
+		var a, b Tree[int, string]
+		a.SetDuplicatePolicy(tt.policy)
+		for _, k := range []int{1, 3, 5} {
+			a.Insert(k, "a")
+		}
+		for _, k := range []int{2, 4} {
+			b.Insert(k, "b")
+		}
+		b.Insert(3, "B")
+		b.Insert(6, "B")
+
+		a.Merge(&b)
+
+		var got []string
+		for k, v := range a.All() {
+			got = append(got, fmt.Sprintf("%d:%s", k, v))
+		}
+		if fmt.Sprint(got) != tt.want {
+			t.Errorf("policy %d: Merge should be %s, got %v", tt.policy, tt.want, got)
+		}
+
+		if b.Len() != 4 {
+			t.Errorf("policy %d: other should be unchanged, got Len %d", tt.policy, b.Len())
+		}
+	}
+
+	// Merging a tree with itself does not deadlock.
+	var c Tree[int, string]
+	c.Insert(1, "c")
+	c.Merge(&c)
+	if c.Len() != 1 {
+		t.Errorf("self Merge should keep 1 key, got %d", c.Len())
+	}
+}
@@ -0,0 +1,130 @@
+package binarysearchtree
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+)
+
+var (
+	// ErrUnsortedKeys the keys to load are not in ascending order
+	ErrUnsortedKeys = errors.New("binarysearchtree: keys are not sorted")
+
+	// ErrLengthMismatch the number of keys and values to load differ
+	ErrLengthMismatch = errors.New("binarysearchtree: keys and values length mismatch")
+)
+
+// encodedTree the serialized form of a tree, keys are sorted in ascending
+// order and values[i] is the value of keys[i]. expires[i] is the expiry
+// time of keys[i] in unix nanoseconds(0 if it never expires), it is omitted
+// when no entry expires.
+type encodedTree[K cmp.Ordered, V any] struct {
+	Keys    []K     `json:"keys"`
+	Values  []V     `json:"values"`
+	Expires []int64 `json:"expires,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler, the tree is encoded as
+// {"keys":[...],"values":[...],"expires":[...]} with the keys in ascending
+// order. Expired entries are skipped.
+func (t *Tree[K, V]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.encode())
+}
+
+// UnmarshalJSON implements json.Unmarshaler, it replaces the content of
+// the tree with a balanced tree built from the encoded keys and values
+func (t *Tree[K, V]) UnmarshalJSON(data []byte) error {
+	var e encodedTree[K, V]
+	if err := json.Unmarshal(data, &e); err != nil {
+		return err
+	}
+	return t.load(e)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, the sorted keys,
+// values and expiry times are encoded with encoding/gob
+func (t *Tree[K, V]) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(t.encode()); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, see UnmarshalJSON
+func (t *Tree[K, V]) UnmarshalBinary(data []byte) error {
+	var e encodedTree[K, V]
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&e); err != nil {
+		return err
+	}
+	return t.load(e)
+}
+
+// internal function to collect the live entries in ascending order
+func (t *Tree[K, V]) encode() encodedTree[K, V] {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return t.entries()
+}
+
+// internal function to replace the content of the tree with decoded keys,
+// values and expiry times
+func (t *Tree[K, V]) load(e encodedTree[K, V]) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if err := checkSorted(e.Keys, e.Values, t.duplicates == DuplicateMultiset); err != nil {
+		return err
+	}
+	if len(e.Expires) > 0 && len(e.Expires) != len(e.Keys) {
+		return ErrLengthMismatch
+	}
+
+	root := buildFromSorted(e.Keys, e.Values)
+	if len(e.Expires) > 0 {
+		for i, n := range appendNodes(root, make([]*Node[K, V], 0, len(e.Keys))) {
+			n.expires = e.Expires[i]
+		}
+	}
+
+	if len(t.watchers) > 0 {
+		t.notifyNodes(EventRemove, appendNodes(t.Root, nil))
+		t.notifyNodes(EventInsert, appendNodes(root, nil))
+	}
+
+	t.Root = root
+	return nil
+}
+
+// internal function to check the keys are sorted in ascending order,
+// strictly unless duplicates are allowed
+func checkSorted[K cmp.Ordered, V any](keys []K, values []V, duplicates bool) error {
+	if len(keys) != len(values) {
+		return ErrLengthMismatch
+	}
+
+	for i := 1; i < len(keys); i++ {
+		if keys[i] < keys[i-1] || (!duplicates && keys[i] == keys[i-1]) {
+			return ErrUnsortedKeys
+		}
+	}
+	return nil
+}
+
+// internal recursive function to build a perfectly balanced subtree from
+// sorted keys and values in O(n)
+func buildFromSorted[K cmp.Ordered, V any](keys []K, values []V) *Node[K, V] {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	mid := len(keys) / 2
+	n := newNode(keys[mid], values[mid])
+	n.Left = buildFromSorted(keys[:mid], values[:mid])
+	n.Right = buildFromSorted(keys[mid+1:], values[mid+1:])
+	update(n)
+	return n
+}
@@ -0,0 +1,117 @@
+package binarysearchtree
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+var (
+	_ json.Marshaler             = (*Tree[int, int])(nil)
+	_ json.Unmarshaler           = (*Tree[int, int])(nil)
+	_ encoding.BinaryMarshaler   = (*Tree[int, int])(nil)
+	_ encoding.BinaryUnmarshaler = (*Tree[int, int])(nil)
+)
+
+func TestMarshalJSON(t *testing.T) {
+	var tr Tree[string, int]
+	for i, k := range []string{"d", "b", "a", "c"} {
+		tr.Insert(k, i)
+	}
+
+	data, err := json.Marshal(&tr)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	const want = `{"keys":["a","b","c","d"],"values":[2,1,3,0]}`
+	if string(data) != want {
+		t.Errorf("Marshal should be %s, got %s", want, data)
+	}
+
+	var got Tree[string, int]
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	for k, v := range tr.All() {
+		if gv, ok := got.Get(k); !ok || gv != v {
+			t.Errorf("Get(%s) should be %d, got %d, %v", k, v, gv, ok)
+		}
+	}
+
+	if got.Len() != 4 {
+		t.Errorf("Len should be 4, got %d", got.Len())
+	}
+}
+
+func TestMarshalBinary(t *testing.T) {
+	// A degenerate tree is reloaded balanced.
+	var tr Tree[int, string]
+	for i := 0; i < 1000; i++ {
+		tr.Insert(i, fmt.Sprint(i))
+	}
+
+	data, err := tr.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary failed: %v", err)
+	}
+
+	got := NewAVLTree[int, string]()
+	got.Put(-1, "replaced")
+	if err := got.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary failed: %v", err)
+	}
+
+	if got.Len() != 1000 || got.Search(-1) {
+		t.Errorf("Len should be 1000 without -1, got %d", got.Len())
+	}
+
+	if h := height(got.Root); h != 10 {
+		t.Errorf("height should be 10, got %d", h)
+	}
+
+	i := 0
+	for k, v := range got.All() {
+		if k != i || v != fmt.Sprint(i) {
+			t.Fatalf("key %d should be %d", k, i)
+		}
+		i++
+	}
+
+	// The reloaded AVL tree keeps balancing.
+	for i := 1000; i < 2000; i++ {
+		got.Insert(i, fmt.Sprint(i))
+	}
+	checkAVL(t, got.Root)
+}
+
+func TestUnmarshalInvalid(t *testing.T) {
+	tests := []struct {
+		data       string
+		duplicates DuplicatePolicy
+		err        error
+	}{
+		{`{"keys":[1,2],"values":["1"]}`, DuplicateReplace, ErrLengthMismatch},
+		{`{"keys":[2,1],"values":["2","1"]}`, DuplicateReplace, ErrUnsortedKeys},
+		{`{"keys":[1,1],"values":["1","1"]}`, DuplicateReplace, ErrUnsortedKeys},
+		{`{"keys":[1,1],"values":["1","1"]}`, DuplicateMultiset, nil},
+	}
+
+	for _, tt := range tests {
+		var tr Tree[int, string]
+		tr.SetDuplicatePolicy(tt.duplicates)
+		tr.Insert(5, "5")
+
+		err := json.Unmarshal([]byte(tt.data), &tr)
+		if err != tt.err {
+			t.Errorf("Unmarshal(%s) should return %v, got %v", tt.data, tt.err, err)
+		}
+
+		// A failed Unmarshal leaves the tree untouched.
+		if err != nil && !tr.Search(5) {
+			t.Errorf("Unmarshal(%s) should not change the tree", tt.data)
+		}
+	}
+}
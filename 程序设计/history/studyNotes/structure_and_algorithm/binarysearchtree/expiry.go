@@ -0,0 +1,152 @@
+package binarysearchtree
+
+import (
+	"cmp"
+	"time"
+)
+
+// PutWithTTL stores the value with key `key` like Put, the entry expires
+// once ttl has elapsed. The lookups and iterators skip an expired entry;
+// it is removed, and given to OnExpire, by the next Insert, Put or Remove
+// of its key, or by ExpireBefore. Until then Len, Rank and Select still
+// count it.
+func (t *Tree[K, V]) PutWithTTL(key K, value V, ttl time.Duration) {
+	t.putExpiring(key, value, t.now().Add(ttl).UnixNano())
+}
+
+// internal function to put an entry expiring at `expires`(0 for never),
+// replacing an expired entry with the same key as if it was missing
+func (t *Tree[K, V]) putExpiring(key K, value V, expires int64) {
+	t.lock.Lock()
+	expired := t.unlinkExpired(key)
+	t.Root = t.put(t.Root, key, value, expires)
+	f := t.onExpire
+	t.lock.Unlock()
+
+	notifyExpired(f, expired)
+}
+
+// OnExpire sets the function called with every entry removed because it
+// expired, by ExpireBefore or by a change of its key. It is called once the
+// lock is released, so it may use the tree.
+func (t *Tree[K, V]) OnExpire(f func(key K, value V)) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.onExpire = f
+}
+
+// ExpireBefore removes the entries that expired before `deadline` and
+// returns their number. It walks the whole tree, and rebuilds it balanced
+// when entries are removed.
+func (t *Tree[K, V]) ExpireBefore(deadline time.Time) int {
+	t.lock.Lock()
+
+	d := deadline.UnixNano()
+	var live, expired []*Node[K, V]
+	for _, n := range appendNodes(t.Root, make([]*Node[K, V], 0, size(t.Root))) {
+		if n.expires != 0 && n.expires < d {
+			expired = append(expired, n)
+		} else {
+			live = append(live, n)
+		}
+	}
+
+	if len(expired) > 0 {
+		t.Root = linkSorted(live)
+		t.notifyNodes(EventRemove, expired)
+	}
+
+	f := t.onExpire
+	t.lock.Unlock()
+
+	notifyExpired(f, expired)
+	return len(expired)
+}
+
+// internal function to find a node with key `key` that has not expired,
+// nil if not found. The caller must hold the lock.
+func (t *Tree[K, V]) lookupLive(n *Node[K, V], key K) *Node[K, V] {
+	return t.lookupExpired(n, key, false)
+}
+
+// internal recursive function to find a node with key `key` which expired
+// or not according to `expired`, nil if not found. A multiset may hold
+// equal keys on both sides of a node, so both are searched when the node
+// doesn't match. The caller must hold the lock.
+func (t *Tree[K, V]) lookupExpired(n *Node[K, V], key K, expired bool) *Node[K, V] {
+	for n != nil {
+		switch {
+		case key < n.Key:
+			n = n.Left
+		case key > n.Key:
+			n = n.Right
+		case t.expired(n) == expired:
+			return n
+		default:
+			if l := t.lookupExpired(n.Left, key, expired); l != nil {
+				return l
+			}
+			n = n.Right
+		}
+	}
+	return nil
+}
+
+// internal function to unlink the expired entries with key `key` before
+// the key changes, it returns them so that the caller calls notifyExpired
+// once the lock is released. The caller must hold the write lock.
+func (t *Tree[K, V]) unlinkExpired(key K) []*Node[K, V] {
+	if t.lookupExpired(t.Root, key, true) == nil {
+		return nil
+	}
+
+	// remove can't choose among equal keys, all of them are unlinked and
+	// the live ones linked again.
+	var removed []*Node[K, V]
+	for {
+		var n *Node[K, V]
+		t.Root, n = t.remove(t.Root, key)
+		if n == nil {
+			break
+		}
+		removed = append(removed, n)
+	}
+
+	var expired []*Node[K, V]
+	for _, n := range removed {
+		if t.expired(n) {
+			expired = append(expired, n)
+		} else {
+			t.Root = t.insertNode(t.Root, n)
+		}
+	}
+
+	t.notifyNodes(EventRemove, expired)
+	return expired
+}
+
+// internal function to check if the node expired, the caller must hold the
+// lock
+func (t *Tree[K, V]) expired(n *Node[K, V]) bool {
+	return n.expires != 0 && n.expires < t.now().UnixNano()
+}
+
+// internal function to get the current time from the clock of the tree
+func (t *Tree[K, V]) now() time.Time {
+	if t.clock != nil {
+		return t.clock()
+	}
+	return time.Now()
+}
+
+// internal function to call the observer with the expired nodes
+func notifyExpired[K cmp.Ordered, V any](f func(K, V), expired []*Node[K, V]) {
+	if f == nil {
+		return
+	}
+
+	for _, n := range expired {
+		f(n.Key, n.Value)
+	}
+}
@@ -0,0 +1,349 @@
+package binarysearchtree
+
+import (
+	"encoding/json"
+	"fmt"
+	"iter"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+)
+
+// expiringTree returns an AVL tree on a fake clock, and the function that
+// moves the clock forward
+func expiringTree() (*Tree[int, string], func(time.Duration)) {
+	now := time.Unix(1000, 0)
+	tr := NewAVLTree[int, string]()
+	tr.clock = func() time.Time { return now }
+	return tr, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestLazyExpiry(t *testing.T) {
+	tr, advance := expiringTree()
+
+	var expired []int
+	tr.OnExpire(func(key int, _ string) { expired = append(expired, key) })
+
+	tr.PutWithTTL(1, "1", time.Second)
+	tr.PutWithTTL(2, "2", time.Minute)
+	tr.Put(3, "3")
+
+	advance(time.Second)
+	if v, ok := tr.Get(1); !ok || v != "1" {
+		t.Errorf("Get(1) should not expire at its expiry time, got %q, %v", v, ok)
+	}
+
+	advance(time.Nanosecond)
+	if _, ok := tr.Get(1); ok {
+		t.Errorf("Get(1) should report the expired entry as missing")
+	}
+	if tr.Search(1) || tr.Len() != 3 {
+		t.Errorf("Get should leave the expired entry in the tree, Len is %d", tr.Len())
+	}
+	if len(expired) != 0 {
+		t.Errorf("OnExpire should not be called by Get, got %v", expired)
+	}
+
+	if !tr.Search(2) || !tr.Search(3) {
+		t.Errorf("entries not expired yet should be found")
+	}
+
+	advance(time.Hour)
+	if !tr.Search(3) {
+		t.Errorf("entries put with Put should never expire")
+	}
+
+	// Delete removes an expired entry, but reports it as missing.
+	if tr.Delete(2) {
+		t.Errorf("Delete(2) should report the expired entry as missing")
+	}
+	if n := tr.ExpireBefore(tr.now()); n != 1 || tr.Len() != 1 {
+		t.Errorf("ExpireBefore should remove 1 entry, removed %d, Len is %d", n, tr.Len())
+	}
+
+	if !slices.Equal(expired, []int{2, 1}) {
+		t.Errorf("OnExpire should be called with [2 1], got %v", expired)
+	}
+
+	// Put replaces the entry and its expiry.
+	tr.PutWithTTL(4, "4", time.Second)
+	tr.Put(4, "four")
+	advance(time.Minute)
+	if v, ok := tr.Get(4); !ok || v != "four" {
+		t.Errorf("Put should clear the expiry, got %q, %v", v, ok)
+	}
+}
+
+func TestLazyExpiryMultiset(t *testing.T) {
+	tr, advance := expiringTree()
+	tr.SetDuplicatePolicy(DuplicateMultiset)
+
+	tr.PutWithTTL(1, "old", time.Second)
+	tr.Insert(1, "new")
+	tr.PutWithTTL(1, "old", time.Second)
+
+	advance(time.Minute)
+	if v, ok := tr.Get(1); !ok || v != "new" {
+		t.Errorf("Get(1) should skip the expired duplicates, got %q, %v", v, ok)
+	}
+	if n := tr.ExpireBefore(tr.now()); n != 1 || tr.Len() != 1 {
+		t.Errorf("ExpireBefore should remove 1 entry, removed %d, Len is %d", n, tr.Len())
+	}
+}
+
+func TestExpiryWrites(t *testing.T) {
+	tr, advance := expiringTree()
+	tr.SetDuplicatePolicy(DuplicateReject)
+
+	var expired []string
+	tr.OnExpire(func(_ int, value string) {
+		expired = append(expired, value)
+		tr.Len()
+	})
+
+	tr.PutWithTTL(1, "a", time.Second)
+	tr.PutWithTTL(2, "b", time.Second)
+	tr.PutWithTTL(3, "c", time.Second)
+	advance(time.Minute)
+
+	// Every write of the key handles the expired entry as missing.
+	if !tr.Insert(1, "A") {
+		t.Errorf("Insert(1) should replace the expired entry")
+	}
+	if _, ok := tr.Remove(2); ok {
+		t.Errorf("Remove(2) should report the expired entry as missing")
+	}
+	tr.Put(3, "C")
+
+	if !slices.Equal(expired, []string{"a", "b", "c"}) {
+		t.Errorf("OnExpire should be called with [a b c], got %v", expired)
+	}
+	if keys := slices.Collect(maps.Keys(maps.Collect(tr.All()))); tr.Len() != 2 || len(keys) != 2 {
+		t.Errorf("the tree should hold 2 entries, Len is %d, keys %v", tr.Len(), keys)
+	}
+	if v, _ := tr.Get(1); v != "A" {
+		t.Errorf("Get(1) should be %q, got %q", "A", v)
+	}
+}
+
+func TestExpiryReads(t *testing.T) {
+	tr, advance := expiringTree()
+	for k := 0; k < 10; k++ {
+		if k%3 == 0 {
+			tr.PutWithTTL(k, fmt.Sprint(k), time.Second)
+		} else {
+			tr.Put(k, fmt.Sprint(k))
+		}
+	}
+	advance(time.Minute)
+
+	// 0, 3, 6 and 9 expired.
+	live := []int{1, 2, 4, 5, 7, 8}
+	keys := func(seq iter.Seq2[int, string]) []int {
+		return slices.Collect(func(yield func(int) bool) {
+			for k := range seq {
+				if !yield(k) {
+					return
+				}
+			}
+		})
+	}
+
+	if got := keys(tr.All()); !slices.Equal(got, live) {
+		t.Errorf("All should be %v, got %v", live, got)
+	}
+	if got := keys(tr.Backward()); !slices.Equal(got, []int{8, 7, 5, 4, 2, 1}) {
+		t.Errorf("Backward should skip the expired keys, got %v", got)
+	}
+	if got := keys(tr.From(3)); !slices.Equal(got, []int{4, 5, 7, 8}) {
+		t.Errorf("From(3) should be [4 5 7 8], got %v", got)
+	}
+	if got := keys(tr.LevelOrder()); len(got) != len(live) {
+		t.Errorf("LevelOrder should skip the expired keys, got %v", got)
+	}
+
+	var got []int
+	tr.Range(3, 6, func(k int, _ string) bool {
+		got = append(got, k)
+		return true
+	})
+	if !slices.Equal(got, []int{4, 5}) {
+		t.Errorf("Range(3, 6) should be [4 5], got %v", got)
+	}
+
+	if tr.Min() != "1" || tr.Max() != "8" {
+		t.Errorf("Min and Max should be 1 and 8, got %s and %s", tr.Min(), tr.Max())
+	}
+
+	for _, c := range []struct {
+		name string
+		f    func(int) (int, string, bool)
+		key  int
+		want int
+	}{
+		{"Floor", tr.Floor, 6, 5},
+		{"Ceiling", tr.Ceiling, 6, 7},
+		{"Predecessor", tr.Predecessor, 4, 2},
+		{"Successor", tr.Successor, 2, 4},
+		{"Successor", tr.Successor, 8, -1},
+		{"Floor", tr.Floor, 0, -1},
+	} {
+		k, _, ok := c.f(c.key)
+		if (c.want == -1 && ok) || (c.want != -1 && (!ok || k != c.want)) {
+			t.Errorf("%s(%d) should be %d, got %d, %v", c.name, c.key, c.want, k, ok)
+		}
+	}
+
+	// The order statistics still count the expired entries.
+	if tr.Len() != 10 || tr.Rank(5) != 5 {
+		t.Errorf("Len and Rank(5) should be 10 and 5, got %d and %d", tr.Len(), tr.Rank(5))
+	}
+}
+
+func TestGetExpiredWhileIterating(t *testing.T) {
+	tr, advance := expiringTree()
+	for k := 0; k < 10; k++ {
+		tr.PutWithTTL(k, "", time.Duration(k+1)*time.Second)
+	}
+	advance(5 * time.Second)
+
+	// The loop body runs without the lock, Get and Put don't deadlock on
+	// expired keys.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for k := range tr.All() {
+			if _, ok := tr.Get(k - 6); ok {
+				t.Errorf("Get(%d) should report the expired entry as missing", k-6)
+			}
+			tr.PutWithTTL(k, "", time.Hour)
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Get inside All deadlocked")
+	}
+
+	if tr.Len() != 10 {
+		t.Errorf("Get should not remove entries, Len is %d", tr.Len())
+	}
+}
+
+func TestExpireBefore(t *testing.T) {
+	tr, advance := expiringTree()
+
+	// The observer may use the tree, the lock is released.
+	var expired []int
+	tr.OnExpire(func(key int, _ string) {
+		expired = append(expired, key)
+		tr.Len()
+	})
+
+	for k := 0; k < 100; k++ {
+		if k%3 == 0 {
+			tr.Put(k, "")
+		} else {
+			tr.PutWithTTL(k, "", time.Duration(k)*time.Second)
+		}
+	}
+
+	if n := tr.ExpireBefore(tr.now()); n != 0 {
+		t.Errorf("ExpireBefore should not remove anything yet, removed %d", n)
+	}
+
+	advance(50 * time.Second)
+	n := tr.ExpireBefore(tr.now())
+
+	var want []int
+	for k := 1; k < 50; k++ {
+		if k%3 != 0 {
+			want = append(want, k)
+		}
+	}
+	if n != len(want) || !slices.Equal(expired, want) {
+		t.Errorf("ExpireBefore should remove %v, got %d %v", want, n, expired)
+	}
+
+	if tr.Len() != 100-len(want) {
+		t.Errorf("Len should be %d, got %d", 100-len(want), tr.Len())
+	}
+
+	checkAVL(t, tr.Root)
+	if err := tr.Validate(); err != nil {
+		t.Error(err)
+	}
+
+	// The remaining entries keep their expiry.
+	advance(time.Hour)
+	tr.ExpireBefore(tr.now())
+	for k := range tr.All() {
+		if k%3 != 0 {
+			t.Errorf("key %d should have expired", k)
+		}
+	}
+}
+
+func TestSplitMergeKeepExpiry(t *testing.T) {
+	tr, advance := expiringTree()
+	for k := 0; k < 10; k++ {
+		tr.PutWithTTL(k, "", time.Duration(k+1)*time.Second)
+	}
+
+	right := tr.Split(5)
+	tr.Merge(right)
+
+	advance(5 * time.Second)
+	if n := tr.ExpireBefore(tr.now()); n != 4 {
+		t.Errorf("ExpireBefore should remove 4 keys, removed %d", n)
+	}
+	if n := right.ExpireBefore(tr.now()); n != 0 {
+		t.Errorf("ExpireBefore on the split tree should remove nothing, removed %d", n)
+	}
+	advance(time.Minute)
+	if n := right.ExpireBefore(tr.now()); n != 5 {
+		t.Errorf("ExpireBefore on the split tree should remove 5 keys, removed %d", n)
+	}
+}
+
+func TestMarshalKeepsExpiry(t *testing.T) {
+	tr, advance := expiringTree()
+	tr.PutWithTTL(1, "expired", time.Second)
+	tr.PutWithTTL(2, "live", time.Hour)
+	tr.Put(3, "forever")
+	advance(time.Minute)
+
+	data, err := json.Marshal(tr)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	bin, err := tr.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary failed: %v", err)
+	}
+
+	for _, unmarshal := range []func(*Tree[int, string]) error{
+		func(got *Tree[int, string]) error { return json.Unmarshal(data, got) },
+		func(got *Tree[int, string]) error { return got.UnmarshalBinary(bin) },
+	} {
+		got, later := expiringTree()
+		later(time.Minute)
+		if err := unmarshal(got); err != nil {
+			t.Fatalf("Unmarshal failed: %v", err)
+		}
+
+		if keys := slices.Sorted(maps.Keys(maps.Collect(got.All()))); !slices.Equal(keys, []int{2, 3}) {
+			t.Errorf("expired entries should not be encoded, got keys %v", keys)
+		}
+
+		later(time.Hour)
+		if _, ok := got.Get(2); ok {
+			t.Errorf("the restored TTL entry should expire")
+		}
+		if v, ok := got.Get(3); !ok || v != "forever" {
+			t.Errorf("Get(3) should be %q, got %q, %v", "forever", v, ok)
+		}
+	}
+}
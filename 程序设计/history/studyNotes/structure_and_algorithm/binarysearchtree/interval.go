@@ -0,0 +1,256 @@
+package binarysearchtree
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"iter"
+)
+
+// ErrInvalidInterval the end of the interval is before its start
+var ErrInvalidInterval = errors.New("binarysearchtree: interval end is before start")
+
+// Interval a closed interval [Start, End], such as a time window or an IP
+// range
+type Interval[K cmp.Ordered] struct {
+	Start K `json:"start"`
+	End   K `json:"end"`
+}
+
+// Overlaps returns true if the interval and [lo, hi] share at least a point
+func (i Interval[K]) Overlaps(lo, hi K) bool {
+	return i.Start <= hi && lo <= i.End
+}
+
+// IntervalTree stores values keyed by intervals and finds the intervals
+// overlapping a range in O(log n + m). It is an AVL Tree keyed by the
+// interval start, each node being augmented with the largest end of its
+// subtree(Thread safe). The zero value is an empty interval tree.
+type IntervalTree[K cmp.Ordered, V any] struct {
+	tree Tree[K, intervalBucket[K, V]]
+}
+
+// intervalBucket the intervals sharing the same start
+type intervalBucket[K cmp.Ordered, V any] struct {
+	entries []intervalEntry[K, V]
+
+	// largest end of the subtree rooted at the node
+	maxEnd K
+}
+
+type intervalEntry[K cmp.Ordered, V any] struct {
+	end   K
+	value V
+}
+
+// NewIntervalTree returns an empty interval tree
+func NewIntervalTree[K cmp.Ordered, V any]() *IntervalTree[K, V] {
+	it := &IntervalTree[K, V]{}
+	it.init()
+	return it
+}
+
+// internal function to make the tree balanced and augmented, a zero value
+// gets it on its first change. The caller must hold the write lock, or own
+// the tree.
+func (it *IntervalTree[K, V]) init() {
+	if it.tree.augment == nil {
+		it.tree.balanced = true
+		it.tree.augment = augmentInterval[K, V]
+	}
+}
+
+// internal function to update the largest end of a subtree
+func augmentInterval[K cmp.Ordered, V any](n *Node[K, intervalBucket[K, V]]) {
+	n.Value.maxEnd = maxEnd(n)
+}
+
+// internal function to compute the largest end of a subtree from the
+// entries of the node and the largest ends of its children
+func maxEnd[K cmp.Ordered, V any](n *Node[K, intervalBucket[K, V]]) K {
+	m := n.Value.entries[0].end
+	for _, e := range n.Value.entries[1:] {
+		m = max(m, e.end)
+	}
+
+	if n.Left != nil {
+		m = max(m, n.Left.Value.maxEnd)
+	}
+	if n.Right != nil {
+		m = max(m, n.Right.Value.maxEnd)
+	}
+	return m
+}
+
+// Insert stores the value with the interval [start, end], the same
+// interval may be stored several times
+func (it *IntervalTree[K, V]) Insert(start, end K, value V) error {
+	if end < start {
+		return ErrInvalidInterval
+	}
+
+	t := &it.tree
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	it.init()
+
+	var b intervalBucket[K, V]
+	if n := lookup(t.Root, start); n != nil {
+		b = n.Value
+	}
+
+	// Copy the entries, the bucket is replaced as a whole.
+	entries := make([]intervalEntry[K, V], len(b.entries), len(b.entries)+1)
+	copy(entries, b.entries)
+	b.entries = append(entries, intervalEntry[K, V]{end: end, value: value})
+
+	t.Root = t.put(t.Root, start, b, 0)
+	return nil
+}
+
+// Delete removes one interval [start, end], it returns its value and false
+// if the interval does not exist
+func (it *IntervalTree[K, V]) Delete(start, end K) (V, bool) {
+	t := &it.tree
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	it.init()
+
+	var zero V
+
+	n := lookup(t.Root, start)
+	if n == nil {
+		return zero, false
+	}
+
+	for i, e := range n.Value.entries {
+		if e.end != end {
+			continue
+		}
+
+		if len(n.Value.entries) == 1 {
+			t.Root, _ = t.remove(t.Root, start)
+			return e.value, true
+		}
+
+		b := n.Value
+		b.entries = make([]intervalEntry[K, V], 0, len(n.Value.entries)-1)
+		b.entries = append(b.entries, n.Value.entries[:i]...)
+		b.entries = append(b.entries, n.Value.entries[i+1:]...)
+		t.Root = t.put(t.Root, start, b, 0)
+		return e.value, true
+	}
+	return zero, false
+}
+
+// Len returns the number of intervals stored in the tree
+func (it *IntervalTree[K, V]) Len() int {
+	t := &it.tree
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	count := 0
+	ascend(t.Root, func(n *Node[K, intervalBucket[K, V]]) bool {
+		count += len(n.Value.entries)
+		return true
+	})
+	return count
+}
+
+// All returns an iterator over the intervals ordered by start, with the
+// same rules as Tree.All
+func (it *IntervalTree[K, V]) All() iter.Seq2[Interval[K], V] {
+	return func(yield func(Interval[K], V) bool) {
+		for start, b := range it.tree.All() {
+			for _, e := range b.entries {
+				if !yield(Interval[K]{start, e.end}, e.value) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Overlapping returns an iterator over the intervals overlapping [lo, hi]
+// ordered by start, with the same rules as Tree.All
+func (it *IntervalTree[K, V]) Overlapping(lo, hi K) iter.Seq2[Interval[K], V] {
+	return func(yield func(Interval[K], V) bool) {
+		type match struct {
+			interval Interval[K]
+			value    V
+		}
+
+		t := &it.tree
+		t.lock.RLock()
+		var matches []match
+		overlapping(t.Root, lo, hi, func(i Interval[K], v V) bool {
+			matches = append(matches, match{i, v})
+			return true
+		})
+		t.lock.RUnlock()
+
+		for _, m := range matches {
+			if !yield(m.interval, m.value) {
+				return
+			}
+		}
+	}
+}
+
+// internal recursive function to visit the intervals overlapping [lo, hi],
+// the subtrees ending before lo or starting after hi are skipped
+func overlapping[K cmp.Ordered, V any](n *Node[K, intervalBucket[K, V]], lo, hi K, yield func(Interval[K], V) bool) bool {
+	if n == nil || n.Value.maxEnd < lo {
+		return true
+	}
+
+	if !overlapping(n.Left, lo, hi, yield) {
+		return false
+	}
+
+	// The right subtree starts after n.Key.
+	if n.Key > hi {
+		return true
+	}
+
+	for _, e := range n.Value.entries {
+		if e.end >= lo && !yield(Interval[K]{n.Key, e.end}, e.value) {
+			return false
+		}
+	}
+	return overlapping(n.Right, lo, hi, yield)
+}
+
+// Validate checks the invariants of the underlying AVL tree and the
+// largest end kept in every node, see Tree.Validate
+func (it *IntervalTree[K, V]) Validate() error {
+	if err := it.tree.Validate(); err != nil {
+		return err
+	}
+
+	it.tree.lock.RLock()
+	defer it.tree.lock.RUnlock()
+
+	var check func(n *Node[K, intervalBucket[K, V]]) error
+	check = func(n *Node[K, intervalBucket[K, V]]) error {
+		if n == nil {
+			return nil
+		}
+
+		if len(n.Value.entries) == 0 {
+			return fmt.Errorf("binarysearchtree: start %v has no interval", n.Key)
+		}
+
+		if m := maxEnd(n); n.Value.maxEnd != m {
+			return fmt.Errorf("binarysearchtree: start %v has max end %v, should be %v", n.Key, n.Value.maxEnd, m)
+		}
+
+		if err := check(n.Left); err != nil {
+			return err
+		}
+		return check(n.Right)
+	}
+	return check(it.tree.Root)
+}
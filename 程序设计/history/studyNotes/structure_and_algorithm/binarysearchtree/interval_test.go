@@ -0,0 +1,178 @@
+package binarysearchtree
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// overlaps returns the intervals overlapping [lo, hi] as strings
+func overlaps(it *IntervalTree[int, string], lo, hi int) []string {
+	var got []string
+	for i, v := range it.Overlapping(lo, hi) {
+		got = append(got, fmt.Sprintf("[%d,%d]%s", i.Start, i.End, v))
+	}
+	return got
+}
+
+func TestIntervalTree(t *testing.T) {
+	it := NewIntervalTree[int, string]()
+
+	for _, i := range []struct {
+		start, end int
+		value      string
+	}{
+		{15, 20, "a"},
+		{10, 30, "b"},
+		{17, 19, "c"},
+		{5, 20, "d"},
+		{12, 15, "e"},
+		{30, 40, "f"},
+		{10, 11, "g"},
+	} {
+		if err := it.Insert(i.start, i.end, i.value); err != nil {
+			t.Fatalf("Insert failed: %v", err)
+		}
+	}
+
+	if err := it.Insert(5, 4, "x"); err != ErrInvalidInterval {
+		t.Errorf("Insert(5, 4) should return ErrInvalidInterval, got %v", err)
+	}
+
+	tests := []struct {
+		lo, hi int
+		want   string
+	}{
+		{14, 16, "[[5,20]d [10,30]b [12,15]e [15,20]a]"},
+		{21, 29, "[[10,30]b]"},
+		{30, 30, "[[10,30]b [30,40]f]"},
+		{0, 4, "[]"},
+		{41, 50, "[]"},
+		{11, 11, "[[5,20]d [10,30]b [10,11]g]"},
+	}
+
+	for _, tt := range tests {
+		if got := fmt.Sprint(overlaps(it, tt.lo, tt.hi)); got != tt.want {
+			t.Errorf("Overlapping(%d, %d) should be %s, got %s", tt.lo, tt.hi, tt.want, got)
+		}
+	}
+
+	if v, ok := it.Delete(10, 30); !ok || v != "b" {
+		t.Errorf("Delete(10, 30) should return \"b\", true, got %q, %v", v, ok)
+	}
+
+	if _, ok := it.Delete(10, 30); ok {
+		t.Errorf("Delete(10, 30) should not be found twice")
+	}
+
+	if _, ok := it.Delete(11, 30); ok {
+		t.Errorf("Delete(11, 30) should not be found")
+	}
+
+	if got := fmt.Sprint(overlaps(it, 21, 29)); got != "[]" {
+		t.Errorf("Overlapping(21, 29) should be empty after Delete, got %s", got)
+	}
+
+	if it.Len() != 6 {
+		t.Errorf("Len should be 6, got %d", it.Len())
+	}
+
+	if err := it.Validate(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestIntervalTreeZeroValue(t *testing.T) {
+	var it IntervalTree[int, string]
+	for k := 0; k < 100; k++ {
+		it.Insert(k, k+10, fmt.Sprint(k))
+	}
+
+	var got []int
+	for i := range it.Overlapping(5, 6) {
+		got = append(got, i.Start)
+	}
+	if !slices.Equal(got, []int{0, 1, 2, 3, 4, 5, 6}) {
+		t.Errorf("Overlapping(5, 6) should start at 0..6, got %v", got)
+	}
+
+	checkAVL(t, it.tree.Root)
+	if err := it.Validate(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestIntervalTreeDeleteWhileIterating(t *testing.T) {
+	it := NewIntervalTree[int, string]()
+	for k := 0; k < 10; k++ {
+		it.Insert(k, k+1, "")
+	}
+
+	// The loop body may modify the tree, it runs without the lock.
+	for i := range it.Overlapping(2, 5) {
+		if _, ok := it.Delete(i.Start, i.End); !ok {
+			t.Errorf("Delete(%d, %d) should succeed", i.Start, i.End)
+		}
+	}
+
+	if it.Len() != 5 {
+		t.Errorf("Len should be 5, got %d", it.Len())
+	}
+	if err := it.Validate(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestIntervalTreeRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	it := NewIntervalTree[int, int]()
+
+	var ref []Interval[int]
+	for i := 0; i < 2000; i++ {
+		if len(ref) > 0 && r.Intn(3) == 0 {
+			k := r.Intn(len(ref))
+			if _, ok := it.Delete(ref[k].Start, ref[k].End); !ok {
+				t.Fatalf("Delete(%d, %d) should succeed", ref[k].Start, ref[k].End)
+			}
+			ref = slices.Delete(ref, k, k+1)
+		} else {
+			start := r.Intn(1000)
+			end := start + r.Intn(50)
+			it.Insert(start, end, i)
+			ref = append(ref, Interval[int]{start, end})
+		}
+
+		if err := it.Validate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for q := 0; q < 200; q++ {
+		lo := r.Intn(1100)
+		hi := lo + r.Intn(30)
+
+		want := 0
+		for _, i := range ref {
+			if i.Overlaps(lo, hi) {
+				want++
+			}
+		}
+
+		got := 0
+		for i := range it.Overlapping(lo, hi) {
+			if !i.Overlaps(lo, hi) {
+				t.Fatalf("[%d,%d] does not overlap [%d,%d]", i.Start, i.End, lo, hi)
+			}
+			got++
+		}
+
+		if got != want {
+			t.Fatalf("Overlapping(%d, %d) should find %d intervals, got %d", lo, hi, want, got)
+		}
+	}
+
+	if it.Len() != len(ref) {
+		t.Errorf("Len should be %d, got %d", len(ref), it.Len())
+	}
+}
@@ -0,0 +1,178 @@
+package binarysearchtree
+
+import (
+	"cmp"
+	"iter"
+)
+
+// All returns an iterator over the keys and values in ascending order,
+// skipping the expired entries. The entries are copied under the read lock
+// when the iteration starts and yielded without holding it, so the loop
+// body may use and modify the tree; its changes are not seen by the
+// iteration.
+func (t *Tree[K, V]) All() iter.Seq2[K, V] {
+	return t.snapshot(ascend[K, V])
+}
+
+// Backward returns an iterator over the keys and values in descending
+// order, with the same rules as All.
+func (t *Tree[K, V]) Backward() iter.Seq2[K, V] {
+	return t.snapshot(descend[K, V])
+}
+
+// From returns an iterator over the keys greater than or equal to `key` in
+// ascending order, with the same rules as All.
+func (t *Tree[K, V]) From(key K) iter.Seq2[K, V] {
+	return t.snapshot(func(n *Node[K, V], yield func(*Node[K, V]) bool) bool {
+		return ascendFrom(n, key, yield)
+	})
+}
+
+// PreOrder returns an iterator visiting the nodes with pre-order
+// traversing, with the same rules as All.
+func (t *Tree[K, V]) PreOrder() iter.Seq2[K, V] {
+	return t.snapshot(preOrder[K, V])
+}
+
+// PostOrder returns an iterator visiting the nodes with post-order
+// traversing, with the same rules as All.
+func (t *Tree[K, V]) PostOrder() iter.Seq2[K, V] {
+	return t.snapshot(postOrder[K, V])
+}
+
+// LevelOrder returns an iterator visiting the nodes level by level from
+// the root, with the same rules as All.
+func (t *Tree[K, V]) LevelOrder() iter.Seq2[K, V] {
+	return t.snapshot(levelOrder[K, V])
+}
+
+// pair a key and its value copied out of the tree
+type pair[K cmp.Ordered, V any] struct {
+	key   K
+	value V
+}
+
+// internal function to return an iterator copying the nodes not expired
+// that walk yields from the root under the read lock, then yielding the
+// copies without the lock
+func (t *Tree[K, V]) snapshot(walk func(*Node[K, V], func(*Node[K, V]) bool) bool) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		t.lock.RLock()
+		pairs := make([]pair[K, V], 0, size(t.Root))
+		walk(t.Root, func(n *Node[K, V]) bool {
+			if !t.expired(n) {
+				pairs = append(pairs, pair[K, V]{n.Key, n.Value})
+			}
+			return true
+		})
+		t.lock.RUnlock()
+
+		for _, p := range pairs {
+			if !yield(p.key, p.value) {
+				return
+			}
+		}
+	}
+}
+
+// internal function to find the first node not expired that walk yields
+// from the root, nil if none. The caller must hold the lock.
+func (t *Tree[K, V]) firstLive(walk func(*Node[K, V], func(*Node[K, V]) bool) bool) *Node[K, V] {
+	var found *Node[K, V]
+	walk(t.Root, func(n *Node[K, V]) bool {
+		if t.expired(n) {
+			return true
+		}
+		found = n
+		return false
+	})
+	return found
+}
+
+// internal function to adapt a function taking keys and values to the
+// walkers below, which yield nodes
+func yieldEntry[K cmp.Ordered, V any](yield func(K, V) bool) func(*Node[K, V]) bool {
+	return func(n *Node[K, V]) bool {
+		return yield(n.Key, n.Value)
+	}
+}
+
+// internal recursive function to iterate in order, it returns false if the
+// iteration was stopped
+func ascend[K cmp.Ordered, V any](n *Node[K, V], yield func(*Node[K, V]) bool) bool {
+	if n == nil {
+		return true
+	}
+	return ascend(n.Left, yield) && yield(n) && ascend(n.Right, yield)
+}
+
+// internal recursive function to iterate in reverse order
+func descend[K cmp.Ordered, V any](n *Node[K, V], yield func(*Node[K, V]) bool) bool {
+	if n == nil {
+		return true
+	}
+	return descend(n.Right, yield) && yield(n) && descend(n.Left, yield)
+}
+
+// internal recursive function to iterate in order from `key`
+func ascendFrom[K cmp.Ordered, V any](n *Node[K, V], key K, yield func(*Node[K, V]) bool) bool {
+	if n == nil {
+		return true
+	}
+
+	if n.Key < key {
+		return ascendFrom(n.Right, key, yield)
+	}
+	return ascendFrom(n.Left, key, yield) && yield(n) && ascend(n.Right, yield)
+}
+
+// internal recursive function to iterate in reverse order from `key`
+func descendFrom[K cmp.Ordered, V any](n *Node[K, V], key K, yield func(*Node[K, V]) bool) bool {
+	if n == nil {
+		return true
+	}
+
+	if n.Key > key {
+		return descendFrom(n.Left, key, yield)
+	}
+	return descendFrom(n.Right, key, yield) && yield(n) && descend(n.Left, yield)
+}
+
+// internal recursive function to iterate pre order
+func preOrder[K cmp.Ordered, V any](n *Node[K, V], yield func(*Node[K, V]) bool) bool {
+	if n == nil {
+		return true
+	}
+	return yield(n) && preOrder(n.Left, yield) && preOrder(n.Right, yield)
+}
+
+// internal recursive function to iterate post order
+func postOrder[K cmp.Ordered, V any](n *Node[K, V], yield func(*Node[K, V]) bool) bool {
+	if n == nil {
+		return true
+	}
+	return postOrder(n.Left, yield) && postOrder(n.Right, yield) && yield(n)
+}
+
+// internal function to iterate level order
+func levelOrder[K cmp.Ordered, V any](n *Node[K, V], yield func(*Node[K, V]) bool) bool {
+	if n == nil {
+		return true
+	}
+
+	queue := []*Node[K, V]{n}
+	for len(queue) > 0 {
+		n, queue = queue[0], queue[1:]
+		if !yield(n) {
+			return false
+		}
+
+		if n.Left != nil {
+			queue = append(queue, n.Left)
+		}
+		if n.Right != nil {
+			queue = append(queue, n.Right)
+		}
+	}
+	return true
+}
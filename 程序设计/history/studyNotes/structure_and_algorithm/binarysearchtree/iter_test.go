@@ -0,0 +1,104 @@
+package binarysearchtree
+
+import (
+	"fmt"
+	"iter"
+	"slices"
+	"testing"
+	"time"
+)
+
+// collect returns the keys of an iterator, stopping after limit keys if
+// limit is positive
+func collect(seq iter.Seq2[int, interface{}], limit int) []int {
+	var keys []int
+	for k := range seq {
+		keys = append(keys, k)
+		if len(keys) == limit {
+			break
+		}
+	}
+	return keys
+}
+
+func TestIterators(t *testing.T) {
+	var tr Tree[int, interface{}]
+	FillTree(&tr)
+
+	tests := []struct {
+		name  string
+		seq   iter.Seq2[int, interface{}]
+		limit int
+		want  string
+	}{
+		{"All", tr.All(), 0, "[1 2 3 5 6 7 8 10 12 14 17 28]"},
+		{"All stop", tr.All(), 4, "[1 2 3 5]"},
+		{"Backward", tr.Backward(), 0, "[28 17 14 12 10 8 7 6 5 3 2 1]"},
+		{"Backward stop", tr.Backward(), 3, "[28 17 14]"},
+		{"From", tr.From(6), 0, "[6 7 8 10 12 14 17 28]"},
+		{"From missing key", tr.From(9), 0, "[10 12 14 17 28]"},
+		{"From stop", tr.From(4), 2, "[5 6]"},
+		{"From after max", tr.From(29), 0, "[]"},
+		{"PreOrder", tr.PreOrder(), 0, "[8 6 3 1 2 5 7 10 12 17 14 28]"},
+		{"PostOrder", tr.PostOrder(), 0, "[2 1 5 3 7 6 14 28 17 12 10 8]"},
+		{"LevelOrder", tr.LevelOrder(), 0, "[8 6 10 3 7 12 1 5 17 2 14 28]"},
+		{"LevelOrder stop", tr.LevelOrder(), 5, "[8 6 10 3 7]"},
+	}
+
+	for _, tt := range tests {
+		if got := fmt.Sprint(collect(tt.seq, tt.limit)); got != tt.want {
+			t.Errorf("%s should be %s, got %s", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestIteratorValues(t *testing.T) {
+	var tr Tree[int, interface{}]
+	FillTree(&tr)
+
+	for k, v := range tr.All() {
+		if v != fmt.Sprint(k) {
+			t.Errorf("value of %d should be %q, got %v", k, fmt.Sprint(k), v)
+		}
+	}
+
+	// The lock is released once the iteration is stopped.
+	for range tr.All() {
+		break
+	}
+	tr.Insert(100, "100")
+}
+
+func TestIteratorLoopBodyUsesTree(t *testing.T) {
+	var tr Tree[int, interface{}]
+	FillTree(&tr)
+	want := collect(tr.All(), 0)
+
+	// The loop body reads and writes the tree, the iteration keeps the
+	// entries it started with.
+	done := make(chan []int)
+	go func() {
+		var keys []int
+		for k := range tr.All() {
+			if _, ok := tr.Get(k); !ok {
+				t.Errorf("Get(%d) should succeed", k)
+			}
+			tr.Put(k+100, "")
+			keys = append(keys, k)
+		}
+		done <- keys
+	}()
+
+	select {
+	case keys := <-done:
+		if !slices.Equal(keys, want) {
+			t.Errorf("the iteration should yield %v, got %v", want, keys)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("using the tree inside All deadlocked")
+	}
+
+	if tr.Len() != 2*len(want) {
+		t.Errorf("Len should be %d, got %d", 2*len(want), tr.Len())
+	}
+}
@@ -0,0 +1,170 @@
+package binarysearchtree
+
+import "cmp"
+
+// size returns the number of nodes in the subtree, 0 for an empty one
+func size[K cmp.Ordered, V any](n *Node[K, V]) int {
+	if n == nil {
+		return 0
+	}
+	return n.size
+}
+
+// Floor returns the largest key less than or equal to `key`, skipping the
+// expired entries
+func (t *Tree[K, V]) Floor(key K) (K, V, bool) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return entry(t.floor(key, true))
+}
+
+// Ceiling returns the smallest key greater than or equal to `key`, skipping
+// the expired entries
+func (t *Tree[K, V]) Ceiling(key K) (K, V, bool) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return entry(t.ceiling(key, true))
+}
+
+// Predecessor returns the largest key strictly less than `key`, skipping
+// the expired entries
+func (t *Tree[K, V]) Predecessor(key K) (K, V, bool) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return entry(t.floor(key, false))
+}
+
+// Successor returns the smallest key strictly greater than `key`, skipping
+// the expired entries
+func (t *Tree[K, V]) Successor(key K) (K, V, bool) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return entry(t.ceiling(key, false))
+}
+
+// internal function to unpack a node, false if the node is nil
+func entry[K cmp.Ordered, V any](n *Node[K, V]) (K, V, bool) {
+	if n == nil {
+		var (
+			key   K
+			value V
+		)
+		return key, value, false
+	}
+	return n.Key, n.Value, true
+}
+
+// internal function to find the largest node not expired less than `key`,
+// or equal to `key` if inclusive is true. The caller must hold the lock.
+func (t *Tree[K, V]) floor(key K, inclusive bool) *Node[K, V] {
+	return t.firstLive(func(n *Node[K, V], yield func(*Node[K, V]) bool) bool {
+		return descendFrom(n, key, func(n *Node[K, V]) bool {
+			return (!inclusive && n.Key == key) || yield(n)
+		})
+	})
+}
+
+// internal function to find the smallest node not expired greater than
+// `key`, or equal to `key` if inclusive is true. The caller must hold the
+// lock.
+func (t *Tree[K, V]) ceiling(key K, inclusive bool) *Node[K, V] {
+	return t.firstLive(func(n *Node[K, V], yield func(*Node[K, V]) bool) bool {
+		return ascendFrom(n, key, func(n *Node[K, V]) bool {
+			return (!inclusive && n.Key == key) || yield(n)
+		})
+	})
+}
+
+// Range visits in order the keys between lo and hi(both included), the
+// iteration stops as soon as f returns false. f is called without holding
+// the lock, as the loop body of All.
+func (t *Tree[K, V]) Range(lo, hi K, f func(K, V) bool) {
+	entries := t.snapshot(func(n *Node[K, V], yield func(*Node[K, V]) bool) bool {
+		return rangeNodes(n, lo, hi, yield)
+	})
+
+	for k, v := range entries {
+		if !f(k, v) {
+			return
+		}
+	}
+}
+
+// internal recursive function to visit a range, it returns false if the
+// iteration was stopped
+func rangeNodes[K cmp.Ordered, V any](n *Node[K, V], lo, hi K, f func(*Node[K, V]) bool) bool {
+	if n == nil {
+		return true
+	}
+
+	if lo <= n.Key && !rangeNodes(n.Left, lo, hi, f) {
+		return false
+	}
+
+	if lo <= n.Key && n.Key <= hi && !f(n) {
+		return false
+	}
+
+	if n.Key <= hi {
+		return rangeNodes(n.Right, lo, hi, f)
+	}
+	return true
+}
+
+// Rank returns the number of keys strictly less than `key`, counting the
+// expired entries not removed yet like Len
+func (t *Tree[K, V]) Rank(key K) int {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return rankOf(t.Root, key)
+}
+
+// internal function to count the keys strictly less than `key`
+func rankOf[K cmp.Ordered, V any](n *Node[K, V], key K) int {
+	rank := 0
+	for n != nil {
+		if n.Key < key {
+			rank += size(n.Left) + 1
+			n = n.Right
+		} else {
+			n = n.Left
+		}
+	}
+	return rank
+}
+
+// Select returns the key with rank `i`, that is the i-th smallest key
+// counting from 0. The expired entries not removed yet are counted like in
+// Rank, so it may return one of them.
+func (t *Tree[K, V]) Select(i int) (K, V, bool) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return entry(selectNode(t.Root, i))
+}
+
+// internal function to find the node with rank `i` in a subtree
+func selectNode[K cmp.Ordered, V any](n *Node[K, V], i int) *Node[K, V] {
+	if i < 0 {
+		return nil
+	}
+
+	for n != nil {
+		l := size(n.Left)
+		switch {
+		case i < l:
+			n = n.Left
+		case i > l:
+			i -= l + 1
+			n = n.Right
+		default:
+			return n
+		}
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package binarysearchtree
+
+import (
+	"fmt"
+	"testing"
+)
+
+// orderTrees returns an unbalanced and an AVL tree holding the even keys
+// from 0 to 98
+func orderTrees() map[string]*Tree[int, string] {
+	trees := map[string]*Tree[int, string]{
+		"Tree":    {},
+		"AVLTree": NewAVLTree[int, string](),
+	}
+
+	for _, tr := range trees {
+		// Insert in a shuffled but deterministic order.
+		for i := 0; i < 50; i++ {
+			k := (i * 37 % 50) * 2
+			tr.Insert(k, fmt.Sprint(k))
+		}
+	}
+	return trees
+}
+
+func TestFloorCeiling(t *testing.T) {
+	tests := []struct {
+		key                      int
+		floor, ceiling, pre, suc int
+	}{
+		// -1 means not found.
+		{-5, -1, 0, -1, 0},
+		{0, 0, 0, -1, 2},
+		{7, 6, 8, 6, 8},
+		{50, 50, 50, 48, 52},
+		{98, 98, 98, 96, -1},
+		{120, 98, -1, 98, -1},
+	}
+
+	check := func(t *testing.T, op string, key, want int, k int, v string, ok bool) {
+		t.Helper()
+
+		if want == -1 {
+			if ok {
+				t.Errorf("%s(%d) should not be found, got %d", op, key, k)
+			}
+			return
+		}
+
+		if !ok || k != want || v != fmt.Sprint(want) {
+			t.Errorf("%s(%d) should be %d, got %d, %q, %v", op, key, want, k, v, ok)
+		}
+	}
+
+	for name, tr := range orderTrees() {
+		t.Run(name, func(t *testing.T) {
+			for _, tt := range tests {
+				k, v, ok := tr.Floor(tt.key)
+				check(t, "Floor", tt.key, tt.floor, k, v, ok)
+
+				k, v, ok = tr.Ceiling(tt.key)
+				check(t, "Ceiling", tt.key, tt.ceiling, k, v, ok)
+
+				k, v, ok = tr.Predecessor(tt.key)
+				check(t, "Predecessor", tt.key, tt.pre, k, v, ok)
+
+				k, v, ok = tr.Successor(tt.key)
+				check(t, "Successor", tt.key, tt.suc, k, v, ok)
+			}
+		})
+	}
+}
+
+func TestRange(t *testing.T) {
+	for name, tr := range orderTrees() {
+		t.Run(name, func(t *testing.T) {
+			var got []int
+			tr.Range(9, 21, func(k int, v string) bool {
+				got = append(got, k)
+				return true
+			})
+
+			if fmt.Sprint(got) != "[10 12 14 16 18 20]" {
+				t.Errorf("Range(9, 21) should be [10 12 14 16 18 20], got %v", got)
+			}
+
+			got = got[:0]
+			tr.Range(0, 98, func(k int, v string) bool {
+				got = append(got, k)
+				return len(got) < 3
+			})
+
+			if fmt.Sprint(got) != "[0 2 4]" {
+				t.Errorf("Range should stop after 3 keys, got %v", got)
+			}
+
+			tr.Range(30, 20, func(k int, v string) bool {
+				t.Errorf("Range(30, 20) should be empty, got %d", k)
+				return true
+			})
+
+			// f may modify the tree, it is called without the lock.
+			tr.Range(0, 9, func(k int, v string) bool {
+				return tr.Delete(k)
+			})
+			if k, _, _ := tr.Select(0); k != 10 {
+				t.Errorf("Range should let f delete the keys up to 9, Min is %d", k)
+			}
+		})
+	}
+}
+
+func TestRankSelect(t *testing.T) {
+	for name, tr := range orderTrees() {
+		t.Run(name, func(t *testing.T) {
+			for i := 0; i < 50; i++ {
+				k, v, ok := tr.Select(i)
+				if !ok || k != i*2 || v != fmt.Sprint(i*2) {
+					t.Errorf("Select(%d) should be %d, got %d, %q, %v", i, i*2, k, v, ok)
+				}
+
+				if r := tr.Rank(i * 2); r != i {
+					t.Errorf("Rank(%d) should be %d, got %d", i*2, i, r)
+				}
+
+				if r := tr.Rank(i*2 + 1); r != i+1 {
+					t.Errorf("Rank(%d) should be %d, got %d", i*2+1, i+1, r)
+				}
+			}
+
+			if _, _, ok := tr.Select(-1); ok {
+				t.Errorf("Select(-1) should not be found")
+			}
+
+			if _, _, ok := tr.Select(50); ok {
+				t.Errorf("Select(50) should not be found")
+			}
+
+			// Sizes are kept up to date by Remove.
+			tr.Remove(10)
+			tr.Remove(0)
+			if k, _, _ := tr.Select(4); k != 12 {
+				t.Errorf("Select(4) should be 12 after Remove, got %d", k)
+			}
+
+			if r := tr.Rank(12); r != 4 || tr.Len() != 48 {
+				t.Errorf("Rank(12) and Len should be 4 and 48, got %d and %d", r, tr.Len())
+			}
+		})
+	}
+}
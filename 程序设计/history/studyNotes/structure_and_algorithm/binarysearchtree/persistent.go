@@ -0,0 +1,229 @@
+package binarysearchtree
+
+import (
+	"cmp"
+	"iter"
+	"sync"
+	"sync/atomic"
+)
+
+// Persistent an immutable AVL tree, Insert and Remove leave the tree
+// untouched and return a new version sharing the unchanged nodes with the
+// old one(path copying). The zero value is an empty tree, and a version can
+// be read from any goroutine without lock.
+type Persistent[K cmp.Ordered, V any] struct {
+	root *Node[K, V]
+}
+
+// Insert returns a new version with the value stored with key `key`,
+// replacing the old value if the key already exists
+func (p Persistent[K, V]) Insert(key K, value V) Persistent[K, V] {
+	return Persistent[K, V]{root: persistentInsert(p.root, key, value)}
+}
+
+// Remove returns a new version without the key `key`, the removed value
+// and false if the key does not exist
+func (p Persistent[K, V]) Remove(key K) (Persistent[K, V], V, bool) {
+	root, removed := persistentRemove(p.root, key)
+	if removed == nil {
+		var zero V
+		return p, zero, false
+	}
+	return Persistent[K, V]{root: root}, removed.Value, true
+}
+
+// Get returns the value stored with key `key`
+func (p Persistent[K, V]) Get(key K) (V, bool) {
+	if n := lookup(p.root, key); n != nil {
+		return n.Value, true
+	}
+
+	var zero V
+	return zero, false
+}
+
+// Len returns the number of nodes stored in the tree
+func (p Persistent[K, V]) Len() int {
+	return size(p.root)
+}
+
+// All returns an iterator over the keys and values in ascending order
+func (p Persistent[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		ascend(p.root, yieldEntry(yield))
+	}
+}
+
+// Backward returns an iterator over the keys and values in descending order
+func (p Persistent[K, V]) Backward() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		descend(p.root, yieldEntry(yield))
+	}
+}
+
+// From returns an iterator over the keys greater than or equal to `key`
+func (p Persistent[K, V]) From(key K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		ascendFrom(p.root, key, yieldEntry(yield))
+	}
+}
+
+// Range visits in order the keys between lo and hi(both included), the
+// iteration stops as soon as f returns false
+func (p Persistent[K, V]) Range(lo, hi K, f func(K, V) bool) {
+	rangeNodes(p.root, lo, hi, yieldEntry(f))
+}
+
+// PersistentTree a Persistent tree shared by goroutines. Writers are
+// serialised and publish a new version, readers take an O(1) Snapshot
+// and never block nor are blocked by writers.
+type PersistentTree[K cmp.Ordered, V any] struct {
+	// serialises the writers
+	lock sync.Mutex
+
+	// current version
+	root atomic.Pointer[Node[K, V]]
+}
+
+// Snapshot returns the current version of the tree
+func (t *PersistentTree[K, V]) Snapshot() Persistent[K, V] {
+	return Persistent[K, V]{root: t.root.Load()}
+}
+
+// Insert stores the value with key `key`, replacing the old value if the
+// key already exists in the tree
+func (t *PersistentTree[K, V]) Insert(key K, value V) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.root.Store(persistentInsert(t.root.Load(), key, value))
+}
+
+// Remove removes the value with key `key` from the tree, it returns the
+// removed value and false if the key does not exist
+func (t *PersistentTree[K, V]) Remove(key K) (V, bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	root, removed := persistentRemove(t.root.Load(), key)
+	if removed == nil {
+		var zero V
+		return zero, false
+	}
+
+	t.root.Store(root)
+	return removed.Value, true
+}
+
+// Get returns the value stored with key `key` in the current version
+func (t *PersistentTree[K, V]) Get(key K) (V, bool) {
+	return t.Snapshot().Get(key)
+}
+
+// Len returns the number of nodes stored in the current version
+func (t *PersistentTree[K, V]) Len() int {
+	return t.Snapshot().Len()
+}
+
+// internal function to copy a node before changing it
+func clone[K cmp.Ordered, V any](n *Node[K, V]) *Node[K, V] {
+	c := *n
+	return &c
+}
+
+// internal function to rebalance a copied node, the children rotated by
+// balance are copied first so that no shared node is changed
+func persistentFix[K cmp.Ordered, V any](n *Node[K, V]) *Node[K, V] {
+	update(n)
+
+	switch bf := height(n.Left) - height(n.Right); {
+	case bf > 1:
+		n.Left = clone(n.Left)
+		if height(n.Left.Left) < height(n.Left.Right) {
+			n.Left.Right = clone(n.Left.Right)
+		}
+	case bf < -1:
+		n.Right = clone(n.Right)
+		if height(n.Right.Right) < height(n.Right.Left) {
+			n.Right.Left = clone(n.Right.Left)
+		}
+	}
+	return balance(n)
+}
+
+// internal recursive function to insert with path copying, it returns the
+// root of the new version
+func persistentInsert[K cmp.Ordered, V any](n *Node[K, V], key K, value V) *Node[K, V] {
+	if n == nil {
+		return newNode(key, value)
+	}
+
+	c := clone(n)
+	switch {
+	case key < n.Key:
+		c.Left = persistentInsert(n.Left, key, value)
+	case key > n.Key:
+		c.Right = persistentInsert(n.Right, key, value)
+	default:
+		c.Value = value
+		return c
+	}
+	return persistentFix(c)
+}
+
+// internal recursive function to remove with path copying, it returns the
+// root of the new version and the removed node(nil if not found)
+func persistentRemove[K cmp.Ordered, V any](n *Node[K, V], key K) (*Node[K, V], *Node[K, V]) {
+	if n == nil {
+		return nil, nil
+	}
+
+	var (
+		c       *Node[K, V]
+		removed *Node[K, V]
+	)
+	switch {
+	case key < n.Key:
+		var left *Node[K, V]
+		if left, removed = persistentRemove(n.Left, key); removed == nil {
+			return n, nil
+		}
+		c = clone(n)
+		c.Left = left
+	case key > n.Key:
+		var right *Node[K, V]
+		if right, removed = persistentRemove(n.Right, key); removed == nil {
+			return n, nil
+		}
+		c = clone(n)
+		c.Right = right
+	default:
+		if n.Left == nil {
+			return n.Right, n
+		}
+
+		if n.Right == nil {
+			return n.Left, n
+		}
+
+		// Replace the node with a copy of the smallest node on the right side.
+		right, successor := persistentRemoveMin(n.Right)
+		c = clone(successor)
+		c.Left, c.Right = n.Left, right
+		removed = n
+	}
+	return persistentFix(c), removed
+}
+
+// internal recursive function to remove the smallest node with path
+// copying, it returns the new root of the subtree and the removed node
+func persistentRemoveMin[K cmp.Ordered, V any](n *Node[K, V]) (*Node[K, V], *Node[K, V]) {
+	if n.Left == nil {
+		return n.Right, n
+	}
+
+	left, min := persistentRemoveMin(n.Left)
+	c := clone(n)
+	c.Left = left
+	return persistentFix(c), min
+}
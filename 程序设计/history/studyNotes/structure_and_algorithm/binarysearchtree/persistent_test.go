@@ -0,0 +1,147 @@
+package binarysearchtree
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestPersistentVersions(t *testing.T) {
+	var v0 Persistent[int, int]
+
+	versions := []Persistent[int, int]{v0}
+	for i := 0; i < 200; i++ {
+		versions = append(versions, versions[i].Insert(i, i))
+	}
+
+	// Every version still holds exactly the keys it was built with.
+	for n, v := range versions {
+		if v.Len() != n {
+			t.Fatalf("version %d: Len should be %d, got %d", n, n, v.Len())
+		}
+
+		checkAVL(t, v.root)
+
+		i := 0
+		for k, val := range v.All() {
+			if k != i || val != i {
+				t.Fatalf("version %d: key %d should be %d", n, k, i)
+			}
+			i++
+		}
+	}
+
+	last := versions[len(versions)-1]
+	replaced := last.Insert(10, 100)
+	if v, _ := last.Get(10); v != 10 {
+		t.Errorf("old version should keep 10, got %d", v)
+	}
+	if v, _ := replaced.Get(10); v != 100 {
+		t.Errorf("new version should have 100, got %d", v)
+	}
+
+	removed := last
+	for i := 0; i < 200; i += 3 {
+		var (
+			v  int
+			ok bool
+		)
+		if removed, v, ok = removed.Remove(i); !ok || v != i {
+			t.Fatalf("Remove(%d) should return %d, true, got %d, %v", i, i, v, ok)
+		}
+		checkAVL(t, removed.root)
+	}
+
+	if _, _, ok := removed.Remove(0); ok {
+		t.Errorf("Remove(0) should not be found twice")
+	}
+
+	if last.Len() != 200 || removed.Len() != 200-67 {
+		t.Errorf("Len should be 200 and %d, got %d and %d", 200-67, last.Len(), removed.Len())
+	}
+
+	for i := 0; i < 200; i++ {
+		if _, ok := last.Get(i); !ok {
+			t.Errorf("old version should keep %d", i)
+		}
+		if _, ok := removed.Get(i); ok != (i%3 != 0) {
+			t.Errorf("Get(%d) should be %v", i, i%3 != 0)
+		}
+	}
+}
+
+func TestPersistentTreeSnapshot(t *testing.T) {
+	var tr PersistentTree[int, string]
+	for i := 0; i < 100; i++ {
+		tr.Insert(i, fmt.Sprint(i))
+	}
+
+	snap := tr.Snapshot()
+	for i := 0; i < 100; i += 2 {
+		tr.Remove(i)
+	}
+	tr.Insert(1000, "1000")
+
+	if snap.Len() != 100 || tr.Len() != 51 {
+		t.Errorf("Len should be 100 and 51, got %d and %d", snap.Len(), tr.Len())
+	}
+
+	var keys []int
+	snap.Range(10, 14, func(k int, v string) bool {
+		keys = append(keys, k)
+		return true
+	})
+	if fmt.Sprint(keys) != "[10 11 12 13 14]" {
+		t.Errorf("snapshot Range(10, 14) should be [10 11 12 13 14], got %v", keys)
+	}
+
+	if _, ok := tr.Get(10); ok {
+		t.Errorf("10 should be removed from the tree")
+	}
+}
+
+func TestPersistentTreeConcurrent(t *testing.T) {
+	var (
+		tr PersistentTree[int, int]
+		wg sync.WaitGroup
+	)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		for i := 0; i < 2000; i++ {
+			tr.Insert(i%500, i)
+			if i%3 == 0 {
+				tr.Remove((i * 7) % 500)
+			}
+		}
+	}()
+
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := 0; i < 200; i++ {
+				snap := tr.Snapshot()
+
+				n, prev := 0, -1
+				for k := range snap.All() {
+					if k <= prev {
+						t.Errorf("snapshot keys out of order, %d after %d", k, prev)
+						return
+					}
+					prev = k
+					n++
+				}
+
+				if n != snap.Len() {
+					t.Errorf("snapshot iterated %d keys, Len is %d", n, snap.Len())
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,63 @@
+package binarysearchtree
+
+import (
+	"cmp"
+	"fmt"
+)
+
+// Validate checks the invariants of the tree: the BST ordering, the
+// height and size kept in every node, and the AVL balance for a tree
+// created by NewAVLTree. It returns nil if the tree is sound.
+func (t *Tree[K, V]) Validate() error {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	_, err := validate(t.Root, nil, nil, t.duplicates == DuplicateMultiset, t.balanced)
+	return err
+}
+
+// Validate checks the invariants of the tree, see Tree.Validate
+func (p Persistent[K, V]) Validate() error {
+	_, err := validate(p.root, nil, nil, false, true)
+	return err
+}
+
+// internal recursive function to check a subtree whose keys must lie in
+// [lo, hi](nil means unbounded), it returns the height of the subtree
+func validate[K cmp.Ordered, V any](n *Node[K, V], lo, hi *K, duplicates, balanced bool) (int, error) {
+	if n == nil {
+		return 0, nil
+	}
+
+	// Equal keys may end up on both sides after rotations.
+	if lo != nil && (n.Key < *lo || (!duplicates && n.Key == *lo)) {
+		return 0, fmt.Errorf("binarysearchtree: key %v is out of order, should be after %v", n.Key, *lo)
+	}
+
+	if hi != nil && (n.Key > *hi || (!duplicates && n.Key == *hi)) {
+		return 0, fmt.Errorf("binarysearchtree: key %v is out of order, should be before %v", n.Key, *hi)
+	}
+
+	l, err := validate(n.Left, lo, &n.Key, duplicates, balanced)
+	if err != nil {
+		return 0, err
+	}
+
+	r, err := validate(n.Right, &n.Key, hi, duplicates, balanced)
+	if err != nil {
+		return 0, err
+	}
+
+	if h := 1 + max(l, r); n.height != h {
+		return 0, fmt.Errorf("binarysearchtree: node %v has height %d, should be %d", n.Key, n.height, h)
+	}
+
+	if s := 1 + size(n.Left) + size(n.Right); n.size != s {
+		return 0, fmt.Errorf("binarysearchtree: node %v has size %d, should be %d", n.Key, n.size, s)
+	}
+
+	if balanced && abs(l-r) > 1 {
+		return 0, fmt.Errorf("binarysearchtree: node %v is not balanced, left height %d, right height %d", n.Key, l, r)
+	}
+	return n.height, nil
+}
@@ -0,0 +1,180 @@
+package binarysearchtree
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	var tr Tree[int, interface{}]
+	FillTree(&tr)
+
+	if err := tr.Validate(); err != nil {
+		t.Fatalf("Validate should succeed, got %v", err)
+	}
+
+	// Attach a node without updating the sizes of its ancestors.
+	tr.Root.Right.Left = &Node[int, interface{}]{Key: 9, height: 1, size: 1}
+	if err := tr.Validate(); err == nil {
+		t.Errorf("Validate should detect the wrong size")
+	}
+
+	tr = Tree[int, interface{}]{}
+	FillTree(&tr)
+	tr.Root.Left.Key = 9
+	if err := tr.Validate(); err == nil {
+		t.Errorf("Validate should detect the wrong order")
+	}
+
+	// A degenerate tree is valid, but not as an AVL tree.
+	avl := NewAVLTree[int, int]()
+	avl.Root = buildFromSorted([]int{1, 2, 3, 4, 5, 6, 7}, make([]int, 7))
+	if err := avl.Validate(); err != nil {
+		t.Fatalf("Validate should succeed, got %v", err)
+	}
+
+	var plain Tree[int, int]
+	for i := 0; i < 5; i++ {
+		plain.Insert(i, i)
+	}
+	if err := plain.Validate(); err != nil {
+		t.Fatalf("Validate should succeed, got %v", err)
+	}
+
+	avl.Root = plain.Root
+	if err := avl.Validate(); err == nil {
+		t.Errorf("Validate should detect the unbalanced AVL tree")
+	}
+}
+
+// fuzzOps applies the operations encoded in data to the tree and to a
+// reference map, and checks both stay identical. Each operation is 2
+// bytes, the operation and the key.
+func fuzzOps(t *testing.T, tr *Tree[int, int], data []byte) {
+	ref := map[int]int{}
+
+	for i := 0; i+1 < len(data); i += 2 {
+		op, key := data[i]%4, int(data[i+1])
+		value := i
+
+		switch op {
+		case 0:
+			tr.Insert(key, value)
+			ref[key] = value
+		case 1:
+			tr.Put(key, value)
+			ref[key] = value
+		case 2:
+			v, ok := tr.Remove(key)
+			rv, rok := ref[key]
+			if ok != rok || v != rv {
+				t.Fatalf("Remove(%d) should return %d, %v, got %d, %v", key, rv, rok, v, ok)
+			}
+			delete(ref, key)
+		case 3:
+			v, ok := tr.Get(key)
+			rv, rok := ref[key]
+			if ok != rok || v != rv {
+				t.Fatalf("Get(%d) should return %d, %v, got %d, %v", key, rv, rok, v, ok)
+			}
+		}
+
+		if err := tr.Validate(); err != nil {
+			t.Fatalf("after op %d on key %d: %v\n%s", op, key, err, tr.ASCII())
+		}
+	}
+
+	keys := make([]int, 0, len(ref))
+	for k := range ref {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	if tr.Len() != len(keys) {
+		t.Fatalf("Len should be %d, got %d", len(keys), tr.Len())
+	}
+
+	i := 0
+	for k, v := range tr.All() {
+		if k != keys[i] || v != ref[k] {
+			t.Fatalf("key %d should be %d with value %d, got %d with value %d", i, keys[i], ref[keys[i]], k, v)
+		}
+		i++
+	}
+
+	for i, k := range keys {
+		if sk, _, _ := tr.Select(i); sk != k || tr.Rank(k) != i {
+			t.Fatalf("Select(%d) and Rank(%d) should be %d and %d", i, k, k, i)
+		}
+	}
+}
+
+func FuzzTree(f *testing.F) {
+	f.Add([]byte{0, 5, 0, 3, 0, 8, 2, 5, 3, 3})
+	f.Add([]byte{0, 1, 0, 2, 0, 3, 0, 4, 0, 5, 2, 1, 2, 4})
+	f.Add([]byte{1, 9, 1, 9, 2, 9, 2, 9, 3, 9})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		fuzzOps(t, &Tree[int, int]{}, data)
+		fuzzOps(t, NewAVLTree[int, int](), data)
+	})
+}
+
+func FuzzPersistent(f *testing.F) {
+	f.Add([]byte{0, 5, 0, 3, 0, 8, 2, 5, 2, 3})
+	f.Add([]byte{0, 1, 0, 2, 0, 3, 0, 4, 0, 5, 2, 1, 2, 4})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var (
+			p        Persistent[int, int]
+			versions []Persistent[int, int]
+			dumps    []string
+		)
+
+		ref := map[int]int{}
+		for i := 0; i+1 < len(data); i += 2 {
+			versions = append(versions, p)
+			dumps = append(dumps, dumpPersistent(p))
+
+			key := int(data[i+1])
+			if data[i]%2 == 0 {
+				p = p.Insert(key, i)
+				ref[key] = i
+			} else {
+				_, found := ref[key]
+
+				var ok bool
+				if p, _, ok = p.Remove(key); ok != found {
+					t.Fatalf("Remove(%d) should return %v, got %v", key, found, ok)
+				}
+				delete(ref, key)
+			}
+
+			if err := p.Validate(); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if p.Len() != len(ref) {
+			t.Fatalf("Len should be %d, got %d", len(ref), p.Len())
+		}
+
+		// Old versions are never changed by later operations.
+		for i, v := range versions {
+			if got := dumpPersistent(v); got != dumps[i] {
+				t.Fatalf("version %d changed from %s to %s", i, dumps[i], got)
+			}
+		}
+	})
+}
+
+// dumpPersistent returns the keys and values of a version as a string
+func dumpPersistent(p Persistent[int, int]) string {
+	var b strings.Builder
+	for k, v := range p.All() {
+		fmt.Fprintf(&b, "%d:%d ", k, v)
+	}
+	return b.String()
+}
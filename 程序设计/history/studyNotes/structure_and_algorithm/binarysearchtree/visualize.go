@@ -0,0 +1,153 @@
+package binarysearchtree
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Stats the shape statistics of a tree
+type Stats struct {
+	// Count is the number of nodes.
+	Count int
+
+	// Height is the number of levels, 0 for an empty tree.
+	Height int
+
+	// AverageDepth is the average number of edges from the root to a node,
+	// so the average cost of a successful search.
+	AverageDepth float64
+
+	// MaxImbalance is the largest difference between the heights of the
+	// left and right subtrees of a node, at most 1 for an AVL tree.
+	MaxImbalance int
+}
+
+// Stats returns the shape statistics of the tree
+func (t *Tree[K, V]) Stats() Stats {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	var (
+		s      = Stats{Count: size(t.Root), Height: height(t.Root)}
+		depths int
+	)
+
+	var walk func(n *Node[K, V], depth int)
+	walk = func(n *Node[K, V], depth int) {
+		if n == nil {
+			return
+		}
+
+		depths += depth
+		s.MaxImbalance = max(s.MaxImbalance, abs(height(n.Left)-height(n.Right)))
+		walk(n.Left, depth+1)
+		walk(n.Right, depth+1)
+	}
+	walk(t.Root, 0)
+
+	if s.Count > 0 {
+		s.AverageDepth = float64(depths) / float64(s.Count)
+	}
+	return s
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// WriteDOT writes the tree structure in the Graphviz DOT language, render
+// it with `dot -Tpng tree.dot -o tree.png`
+func (t *Tree[K, V]) WriteDOT(w io.Writer) error {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "digraph Tree {")
+	fmt.Fprintln(bw, "\tnode [shape=circle];")
+
+	// Nodes are named after their in-order index, so that duplicate keys
+	// get distinct names.
+	id := 0
+	var walk func(n *Node[K, V]) int
+	walk = func(n *Node[K, V]) int {
+		l := -1
+		if n.Left != nil {
+			l = walk(n.Left)
+		}
+
+		self := id
+		id++
+		fmt.Fprintf(bw, "\tn%d [label=%s];\n", self, strconv.Quote(fmt.Sprint(n.Key)))
+
+		r := -1
+		if n.Right != nil {
+			r = walk(n.Right)
+		}
+
+		// An invisible node keeps a single child on its side.
+		for _, child := range []int{l, r} {
+			if child >= 0 {
+				fmt.Fprintf(bw, "\tn%d -> n%d;\n", self, child)
+			} else if l >= 0 || r >= 0 {
+				fmt.Fprintf(bw, "\tnil%d [style=invis];\n\tn%d -> nil%d [style=invis];\n", self, self, self)
+			}
+		}
+		return self
+	}
+
+	if t.Root != nil {
+		walk(t.Root)
+	}
+
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+// ASCII returns a terminal rendering of the tree, rotated 90 degrees
+// counter-clockwise: the root is on the left and the right subtree on top.
+//
+//	    ┌── 12
+//	┌── 10
+//	8
+//	└── 6
+//	    └── 3
+func (t *Tree[K, V]) ASCII() string {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	if t.Root == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	renderASCII(&b, t.Root.Right, "", false)
+	fmt.Fprintln(&b, t.Root.Key)
+	renderASCII(&b, t.Root.Left, "", true)
+	return b.String()
+}
+
+// internal recursive function to render a subtree, prefix is the
+// indentation drawn for the ancestors
+func renderASCII[K cmp.Ordered, V any](b *strings.Builder, n *Node[K, V], prefix string, left bool) {
+	if n == nil {
+		return
+	}
+
+	// The line joining a node to its parent runs through the subtree
+	// between them.
+	above, below, branch := "    ", "│   ", "┌── "
+	if left {
+		above, below, branch = below, above, "└── "
+	}
+
+	renderASCII(b, n.Right, prefix+above, false)
+	fmt.Fprintf(b, "%s%s%v\n", prefix, branch, n.Key)
+	renderASCII(b, n.Left, prefix+below, true)
+}
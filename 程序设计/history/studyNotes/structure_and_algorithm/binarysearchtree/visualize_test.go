@@ -0,0 +1,87 @@
+package binarysearchtree
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestASCII(t *testing.T) {
+	var tr Tree[int, interface{}]
+	FillTree(&tr)
+
+	const want = `            ┌── 28
+        ┌── 17
+        │   └── 14
+    ┌── 12
+┌── 10
+8
+│   ┌── 7
+└── 6
+    │   ┌── 5
+    └── 3
+        │   ┌── 2
+        └── 1
+`
+	if got := tr.ASCII(); got != want {
+		t.Errorf("ASCII should be\n%s\ngot\n%s", want, got)
+	}
+
+	var empty Tree[int, int]
+	if got := empty.ASCII(); got != "" {
+		t.Errorf("ASCII of an empty tree should be empty, got %q", got)
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	var tr Tree[string, int]
+	tr.Insert("b", 0)
+	tr.Insert("a", 0)
+	tr.Insert("c", 0)
+	tr.Insert("d", 0)
+
+	const want = `digraph Tree {
+	node [shape=circle];
+	n0 [label="a"];
+	n1 [label="b"];
+	n2 [label="c"];
+	n3 [label="d"];
+	nil2 [style=invis];
+	n2 -> nil2 [style=invis];
+	n2 -> n3;
+	n1 -> n0;
+	n1 -> n2;
+}
+`
+	var b strings.Builder
+	if err := tr.WriteDOT(&b); err != nil {
+		t.Fatalf("WriteDOT failed: %v", err)
+	}
+
+	if b.String() != want {
+		t.Errorf("WriteDOT should be\n%s\ngot\n%s", want, b.String())
+	}
+}
+
+func TestStats(t *testing.T) {
+	var tr Tree[int, interface{}]
+	FillTree(&tr)
+
+	// Depths: 8:0, 6 10:1, 3 7 12:2, 1 5 17:3, 2 14 28:4.
+	want := Stats{Count: 12, Height: 5, AverageDepth: 29.0 / 12, MaxImbalance: 3}
+	if s := tr.Stats(); s != want {
+		t.Errorf("Stats should be %+v, got %+v", want, s)
+	}
+
+	avl := NewAVLTree[int, int]()
+	for i := 0; i < 1000; i++ {
+		avl.Insert(i, i)
+	}
+	if s := avl.Stats(); s.Count != 1000 || s.Height > 11 || s.MaxImbalance > 1 {
+		t.Errorf("AVL Stats should be balanced, got %+v", s)
+	}
+
+	var empty Tree[int, int]
+	if s := empty.Stats(); s != (Stats{}) {
+		t.Errorf("Stats of an empty tree should be zero, got %+v", s)
+	}
+}
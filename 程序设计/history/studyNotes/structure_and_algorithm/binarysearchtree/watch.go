@@ -0,0 +1,155 @@
+package binarysearchtree
+
+import (
+	"cmp"
+	"errors"
+)
+
+// DefaultWatchBuffer the number of events buffered by a watcher when the
+// buffer given to Watch is not positive
+const DefaultWatchBuffer = 64
+
+// ErrWatchOverflow is returned by Watcher.Err once the watcher was closed
+// because its buffer was full
+var ErrWatchOverflow = errors.New("binarysearchtree: watcher buffer overflow")
+
+// EventType the kind of change reported to watchers
+type EventType int
+
+const (
+	// EventInsert a new key was stored
+	EventInsert EventType = iota
+
+	// EventUpdate the value of an existing key was replaced
+	EventUpdate
+
+	// EventRemove a key was removed, deleted or expired
+	EventRemove
+)
+
+func (e EventType) String() string {
+	switch e {
+	case EventInsert:
+		return "insert"
+	case EventUpdate:
+		return "update"
+	case EventRemove:
+		return "remove"
+	}
+	return "unknown"
+}
+
+// Event a change of the tree, Value is the new value, or the removed one
+// for EventRemove, and OldValue the replaced one for EventUpdate
+type Event[K cmp.Ordered, V any] struct {
+	Type     EventType
+	Key      K
+	Value    V
+	OldValue V
+}
+
+// Watcher receives the changes of a tree on a buffered channel, see Watch
+type Watcher[K cmp.Ordered, V any] struct {
+	tree *Tree[K, V]
+	ch   chan Event[K, V]
+
+	// only the keys between lo and hi(both included) are watched if ranged
+	ranged bool
+	lo, hi K
+
+	// why the watcher was closed by the tree, guarded by the tree lock
+	err error
+}
+
+// Watch registers a watcher receiving every change of the tree, buffer is
+// the number of events it can hold. The events are sent in the order of
+// the changes without blocking the writers; a watcher too slow to keep up
+// with them is closed and Err returns ErrWatchOverflow, the caller then has
+// to resynchronise from the tree.
+func (t *Tree[K, V]) Watch(buffer int) *Watcher[K, V] {
+	return t.watch(&Watcher[K, V]{}, buffer)
+}
+
+// WatchRange registers a watcher like Watch, receiving only the changes of
+// the keys between lo and hi(both included)
+func (t *Tree[K, V]) WatchRange(lo, hi K, buffer int) *Watcher[K, V] {
+	return t.watch(&Watcher[K, V]{ranged: true, lo: lo, hi: hi}, buffer)
+}
+
+// internal function to register a watcher
+func (t *Tree[K, V]) watch(w *Watcher[K, V], buffer int) *Watcher[K, V] {
+	if buffer <= 0 {
+		buffer = DefaultWatchBuffer
+	}
+
+	w.tree = t
+	w.ch = make(chan Event[K, V], buffer)
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.watchers = append(t.watchers, w)
+	return w
+}
+
+// Events returns the channel the events are sent on, it is closed with the
+// watcher
+func (w *Watcher[K, V]) Events() <-chan Event[K, V] {
+	return w.ch
+}
+
+// Close unregisters the watcher and closes its channel, the events still
+// buffered can be received
+func (w *Watcher[K, V]) Close() {
+	w.tree.lock.Lock()
+	defer w.tree.lock.Unlock()
+
+	w.tree.unwatch(w, nil)
+}
+
+// Err returns ErrWatchOverflow if the watcher was closed because its buffer
+// was full, nil otherwise
+func (w *Watcher[K, V]) Err() error {
+	w.tree.lock.RLock()
+	defer w.tree.lock.RUnlock()
+
+	return w.err
+}
+
+// internal function to unregister a watcher and close it with err, the
+// caller must hold the lock
+func (t *Tree[K, V]) unwatch(w *Watcher[K, V], err error) {
+	for i, o := range t.watchers {
+		if o == w {
+			// Copy the slice, notify may be ranging over it.
+			t.watchers = append(t.watchers[:i:i], t.watchers[i+1:]...)
+			w.err = err
+			close(w.ch)
+			return
+		}
+	}
+}
+
+// internal function to send a change to the watchers, the caller must hold
+// the lock
+func (t *Tree[K, V]) notify(e Event[K, V]) {
+	for _, w := range t.watchers {
+		if w.ranged && (e.Key < w.lo || e.Key > w.hi) {
+			continue
+		}
+
+		select {
+		case w.ch <- e:
+		default:
+			t.unwatch(w, ErrWatchOverflow)
+		}
+	}
+}
+
+// internal function to send the same change of many nodes to the watchers,
+// the caller must hold the lock
+func (t *Tree[K, V]) notifyNodes(typ EventType, nodes []*Node[K, V]) {
+	for _, n := range nodes {
+		t.notify(Event[K, V]{Type: typ, Key: n.Key, Value: n.Value})
+	}
+}
@@ -0,0 +1,203 @@
+package binarysearchtree
+
+import (
+	"cmp"
+	"slices"
+	"testing"
+	"time"
+)
+
+// drain returns the events buffered by the watcher
+func drain[K cmp.Ordered, V any](w *Watcher[K, V]) []Event[K, V] {
+	var events []Event[K, V]
+	for {
+		select {
+		case e, ok := <-w.Events():
+			if !ok {
+				return events
+			}
+			events = append(events, e)
+		default:
+			return events
+		}
+	}
+}
+
+func TestWatch(t *testing.T) {
+	tr := NewAVLTree[int, string]()
+	tr.Put(1, "a")
+
+	w := tr.Watch(0)
+	defer w.Close()
+
+	tr.Put(2, "b")
+	tr.Put(2, "B")
+	tr.Insert(3, "c")
+	tr.Insert(3, "C")
+	tr.Delete(1)
+	tr.Delete(4)
+
+	want := []Event[int, string]{
+		{Type: EventInsert, Key: 2, Value: "b"},
+		{Type: EventUpdate, Key: 2, Value: "B", OldValue: "b"},
+		{Type: EventInsert, Key: 3, Value: "c"},
+		{Type: EventUpdate, Key: 3, Value: "C", OldValue: "c"},
+		{Type: EventRemove, Key: 1, Value: "a"},
+	}
+	if got := drain(w); !slices.Equal(got, want) {
+		t.Errorf("events should be %v, got %v", want, got)
+	}
+
+	// Rejected duplicates change nothing.
+	tr.SetDuplicatePolicy(DuplicateReject)
+	tr.Insert(3, "x")
+	if got := drain(w); len(got) != 0 {
+		t.Errorf("a rejected Insert should not send events, got %v", got)
+	}
+}
+
+func TestWatchRange(t *testing.T) {
+	tr := NewAVLTree[int, int]()
+	w := tr.WatchRange(10, 20, 0)
+	defer w.Close()
+
+	for k := 0; k < 30; k++ {
+		tr.Put(k, k)
+	}
+
+	var keys []int
+	for _, e := range drain(w) {
+		keys = append(keys, e.Key)
+	}
+	if want := []int{10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20}; !slices.Equal(keys, want) {
+		t.Errorf("the watcher should only see %v, got %v", want, keys)
+	}
+}
+
+func TestWatchOverflow(t *testing.T) {
+	tr := NewAVLTree[int, int]()
+	slow := tr.Watch(2)
+	fast := tr.Watch(10)
+
+	for k := 0; k < 5; k++ {
+		tr.Put(k, k)
+	}
+
+	// The buffered events can still be received before the close.
+	if got := drain(slow); len(got) != 2 {
+		t.Errorf("the slow watcher should receive 2 events, got %v", got)
+	}
+	if _, ok := <-slow.Events(); ok {
+		t.Errorf("the slow watcher should be closed")
+	}
+	if slow.Err() != ErrWatchOverflow {
+		t.Errorf("Err should return ErrWatchOverflow, got %v", slow.Err())
+	}
+
+	// The other watchers are not affected.
+	if got := drain(fast); len(got) != 5 || fast.Err() != nil {
+		t.Errorf("the fast watcher should receive 5 events, got %v, %v", got, fast.Err())
+	}
+
+	fast.Close()
+	fast.Close()
+	slow.Close()
+	if _, ok := <-fast.Events(); ok || fast.Err() != nil {
+		t.Errorf("Close should close the channel without error")
+	}
+
+	tr.Put(10, 10)
+	if len(tr.watchers) != 0 {
+		t.Errorf("closed watchers should be unregistered")
+	}
+}
+
+func TestWatchBulk(t *testing.T) {
+	tr := NewAVLTree[int, int]()
+	for k := 0; k < 6; k++ {
+		tr.Put(k, k)
+	}
+
+	w := tr.Watch(100)
+	defer w.Close()
+
+	right := tr.Split(4)
+	other := NewAVLTree[int, int]()
+	other.Put(3, 30)
+	other.Put(7, 70)
+	tr.Merge(other)
+
+	want := []Event[int, int]{
+		{Type: EventRemove, Key: 4, Value: 4},
+		{Type: EventRemove, Key: 5, Value: 5},
+		{Type: EventUpdate, Key: 3, Value: 30, OldValue: 3},
+		{Type: EventInsert, Key: 7, Value: 70},
+	}
+	if got := drain(w); !slices.Equal(got, want) {
+		t.Errorf("events should be %v, got %v", want, got)
+	}
+
+	data, _ := right.MarshalJSON()
+	if err := tr.UnmarshalJSON(data); err != nil {
+		t.Fatal(err)
+	}
+
+	var removed, inserted int
+	for _, e := range drain(w) {
+		switch e.Type {
+		case EventRemove:
+			removed++
+		case EventInsert:
+			inserted++
+		}
+	}
+	if removed != 5 || inserted != 2 {
+		t.Errorf("UnmarshalJSON should remove 5 and insert 2 keys, got %d, %d", removed, inserted)
+	}
+}
+
+func TestWatchExpiry(t *testing.T) {
+	tr, advance := expiringTree()
+	w := tr.Watch(0)
+	defer w.Close()
+
+	tr.PutWithTTL(1, "1", time.Second)
+	tr.PutWithTTL(2, "2", time.Second)
+	advance(time.Minute)
+	tr.Get(1)
+	tr.ExpireBefore(tr.now())
+
+	want := []Event[int, string]{
+		{Type: EventInsert, Key: 1, Value: "1"},
+		{Type: EventInsert, Key: 2, Value: "2"},
+		{Type: EventRemove, Key: 1, Value: "1"},
+		{Type: EventRemove, Key: 2, Value: "2"},
+	}
+	if got := drain(w); !slices.Equal(got, want) {
+		t.Errorf("events should be %v, got %v", want, got)
+	}
+}
+
+func TestWatchConcurrent(t *testing.T) {
+	tr := NewAVLTree[int, int]()
+	w := tr.Watch(10)
+
+	done := make(chan int)
+	go func() {
+		n := 0
+		for range w.Events() {
+			n++
+		}
+		done <- n
+	}()
+
+	for k := 0; k < 1000; k++ {
+		tr.Put(k%100, k)
+	}
+	w.Close()
+
+	// The reader may be too slow, the watcher is then closed early.
+	if n := <-done; n != 1000 && w.Err() != ErrWatchOverflow {
+		t.Errorf("the watcher should receive 1000 events or overflow, got %d, %v", n, w.Err())
+	}
+}
@@ -0,0 +1,568 @@
+package btree
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"iter"
+	"slices"
+	"sync"
+)
+
+// DefaultDegree a good default minimum degree for small keys and values
+const DefaultDegree = 32
+
+// ErrInvalidDegree the minimum degree of a B-tree is at least 2
+var ErrInvalidDegree = errors.New("btree: invalid degree")
+
+// node a B-tree node holding between degree-1 and 2*degree-1 sorted keys
+// (the root may hold fewer), an inner node has len(keys)+1 children
+type node[K cmp.Ordered, V any] struct {
+	keys     []K
+	values   []V
+	children []*node[K, V]
+
+	// number of keys in the subtree rooted at this node
+	size int
+}
+
+// BTree the order-statistic B-tree(Thread safe). Keys are stored in
+// contiguous arrays, so it is faster and lighter on the GC than a binary
+// search tree for large datasets. The zero value is an empty B-tree with
+// the minimum degree DefaultDegree.
+type BTree[K cmp.Ordered, V any] struct {
+	root *node[K, V]
+
+	// minimum degree, nodes hold at most 2*degree-1 keys. 0 until the
+	// first Put on a zero value, which sets it to DefaultDegree.
+	degree int
+
+	// read only operations take the read lock, so they run in parallel
+	lock sync.RWMutex
+}
+
+// New returns an empty B-tree with the minimum degree `degree`
+func New[K cmp.Ordered, V any](degree int) (*BTree[K, V], error) {
+	if degree < 2 {
+		return nil, ErrInvalidDegree
+	}
+	return &BTree[K, V]{degree: degree}, nil
+}
+
+// Get returns the value stored with key `key`
+func (t *BTree[K, V]) Get(key K) (V, bool) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	n := t.root
+	for n != nil {
+		i, found := slices.BinarySearch(n.keys, key)
+		if found {
+			return n.values[i], true
+		}
+
+		if n.leaf() {
+			break
+		}
+		n = n.children[i]
+	}
+
+	var zero V
+	return zero, false
+}
+
+// Put stores the value with key `key`, replacing the old value if the key
+// already exists in the tree
+func (t *BTree[K, V]) Put(key K, value V) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.root == nil {
+		if t.degree == 0 {
+			t.degree = DefaultDegree
+		}
+		t.root = t.newNode(false)
+	}
+
+	// Split a full root first, the tree grows from the top.
+	if len(t.root.keys) == t.maxKeys() {
+		root := t.newNode(true)
+		root.children = append(root.children, t.root)
+		t.splitChild(root, 0)
+		root.recount()
+		t.root = root
+	}
+
+	t.insertNonFull(t.root, key, value)
+}
+
+// Delete removes the key `key` from the tree, it returns false if the key
+// does not exist
+func (t *BTree[K, V]) Delete(key K) bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.root == nil {
+		return false
+	}
+
+	_, ok := t.remove(t.root, key)
+
+	// Shrink an empty root, the tree shrinks from the top.
+	if len(t.root.keys) == 0 {
+		if t.root.leaf() {
+			t.root = nil
+		} else {
+			t.root = t.root.children[0]
+		}
+	}
+	return ok
+}
+
+// Len returns the number of keys stored in the tree
+func (t *BTree[K, V]) Len() int {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return size(t.root)
+}
+
+// All returns an iterator over the keys and values in ascending order.
+// The tree is read locked during the iteration, so the loop body must not
+// modify the tree.
+func (t *BTree[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		t.lock.RLock()
+		defer t.lock.RUnlock()
+
+		ascend(t.root, yield)
+	}
+}
+
+// Range visits in order the keys between lo and hi(both included), the
+// iteration stops as soon as f returns false
+func (t *BTree[K, V]) Range(lo, hi K, f func(K, V) bool) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	rangeNodes(t.root, lo, hi, f)
+}
+
+// Rank returns the number of keys strictly less than `key`
+func (t *BTree[K, V]) Rank(key K) int {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	rank := 0
+	n := t.root
+	for n != nil {
+		i, found := slices.BinarySearch(n.keys, key)
+		rank += i
+		if n.leaf() {
+			break
+		}
+
+		for _, c := range n.children[:i] {
+			rank += c.size
+		}
+
+		if found {
+			// The child left to the key is entirely less than it.
+			rank += n.children[i].size
+			break
+		}
+		n = n.children[i]
+	}
+	return rank
+}
+
+// Select returns the key with rank `i`, that is the i-th smallest key
+// counting from 0
+func (t *BTree[K, V]) Select(i int) (K, V, bool) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	n := t.root
+	if i < 0 || i >= size(n) {
+		var (
+			key   K
+			value V
+		)
+		return key, value, false
+	}
+
+	for {
+		if n.leaf() {
+			return n.keys[i], n.values[i], true
+		}
+
+		j := 0
+		for ; i >= n.children[j].size; j++ {
+			i -= n.children[j].size
+			if i == 0 {
+				return n.keys[j], n.values[j], true
+			}
+			i--
+		}
+		n = n.children[j]
+	}
+}
+
+// size returns the number of keys in the subtree, 0 for an empty one
+func size[K cmp.Ordered, V any](n *node[K, V]) int {
+	if n == nil {
+		return 0
+	}
+	return n.size
+}
+
+func (n *node[K, V]) leaf() bool {
+	return len(n.children) == 0
+}
+
+// recount recomputes the size of the node from its keys and children
+func (n *node[K, V]) recount() {
+	s := len(n.keys)
+	for _, c := range n.children {
+		s += c.size
+	}
+	n.size = s
+}
+
+func (t *BTree[K, V]) maxKeys() int {
+	return 2*t.degree - 1
+}
+
+// internal function to allocate a node with the capacity of a full one
+func (t *BTree[K, V]) newNode(inner bool) *node[K, V] {
+	n := &node[K, V]{
+		keys:   make([]K, 0, t.maxKeys()),
+		values: make([]V, 0, t.maxKeys()),
+	}
+	if inner {
+		n.children = make([]*node[K, V], 0, t.maxKeys()+1)
+	}
+	return n
+}
+
+// internal function to split the full child i of n around its median key,
+// which moves up into n
+func (t *BTree[K, V]) splitChild(n *node[K, V], i int) {
+	c := n.children[i]
+	mid := t.degree - 1
+
+	right := t.newNode(!c.leaf())
+	right.keys = append(right.keys, c.keys[mid+1:]...)
+	right.values = append(right.values, c.values[mid+1:]...)
+	if !c.leaf() {
+		right.children = append(right.children, c.children[mid+1:]...)
+		clear(c.children[mid+1:])
+		c.children = c.children[:mid+1]
+	}
+
+	n.keys = slices.Insert(n.keys, i, c.keys[mid])
+	n.values = slices.Insert(n.values, i, c.values[mid])
+	n.children = slices.Insert(n.children, i+1, right)
+
+	clear(c.keys[mid:])
+	clear(c.values[mid:])
+	c.keys, c.values = c.keys[:mid], c.values[:mid]
+
+	c.recount()
+	right.recount()
+}
+
+// internal recursive function to insert into a node which is not full, it
+// returns true if a key was added
+func (t *BTree[K, V]) insertNonFull(n *node[K, V], key K, value V) bool {
+	i, found := slices.BinarySearch(n.keys, key)
+	if found {
+		n.values[i] = value
+		return false
+	}
+
+	if n.leaf() {
+		n.keys = slices.Insert(n.keys, i, key)
+		n.values = slices.Insert(n.values, i, value)
+		n.size++
+		return true
+	}
+
+	// Split a full child before going down, so that it can take the key.
+	if len(n.children[i].keys) == t.maxKeys() {
+		t.splitChild(n, i)
+
+		switch {
+		case key == n.keys[i]:
+			n.values[i] = value
+			return false
+		case key > n.keys[i]:
+			i++
+		}
+	}
+
+	added := t.insertNonFull(n.children[i], key, value)
+	if added {
+		n.size++
+	}
+	return added
+}
+
+// internal recursive function to remove a key from the subtree, every
+// node it goes down to holds at least degree keys so that it can lose one
+func (t *BTree[K, V]) remove(n *node[K, V], key K) (V, bool) {
+	i, found := slices.BinarySearch(n.keys, key)
+
+	if n.leaf() {
+		if !found {
+			var zero V
+			return zero, false
+		}
+
+		v := n.values[i]
+		n.keys = slices.Delete(n.keys, i, i+1)
+		n.values = slices.Delete(n.values, i, i+1)
+		n.size--
+		return v, true
+	}
+
+	if found {
+		v := n.values[i]
+
+		switch {
+		case len(n.children[i].keys) >= t.degree:
+			// Replace the key with its predecessor.
+			c := n.children[i]
+			for !c.leaf() {
+				c = c.children[len(c.children)-1]
+			}
+			k := c.keys[len(c.keys)-1]
+			n.values[i], _ = t.remove(n.children[i], k)
+			n.keys[i] = k
+		case len(n.children[i+1].keys) >= t.degree:
+			// Replace the key with its successor.
+			c := n.children[i+1]
+			for !c.leaf() {
+				c = c.children[0]
+			}
+			k := c.keys[0]
+			n.values[i], _ = t.remove(n.children[i+1], k)
+			n.keys[i] = k
+		default:
+			// Both children are minimal, merge them around the key.
+			t.merge(n, i)
+			t.remove(n.children[i], key)
+		}
+
+		n.size--
+		return v, true
+	}
+
+	if len(n.children[i].keys) < t.degree {
+		i = t.fill(n, i)
+	}
+
+	v, ok := t.remove(n.children[i], key)
+	if ok {
+		n.size--
+	}
+	return v, ok
+}
+
+// internal function to give the minimal child i of n one more key, by
+// borrowing from a sibling or merging with it. It returns the index of
+// the child which holds the keys of child i afterwards.
+func (t *BTree[K, V]) fill(n *node[K, V], i int) int {
+	switch {
+	case i > 0 && len(n.children[i-1].keys) >= t.degree:
+		t.borrowFromPrev(n, i)
+		return i
+	case i < len(n.keys) && len(n.children[i+1].keys) >= t.degree:
+		t.borrowFromNext(n, i)
+		return i
+	case i < len(n.keys):
+		t.merge(n, i)
+		return i
+	default:
+		t.merge(n, i-1)
+		return i - 1
+	}
+}
+
+// internal function to rotate the last key of child i-1 through n into
+// child i
+func (t *BTree[K, V]) borrowFromPrev(n *node[K, V], i int) {
+	c, s := n.children[i], n.children[i-1]
+	last := len(s.keys) - 1
+
+	c.keys = slices.Insert(c.keys, 0, n.keys[i-1])
+	c.values = slices.Insert(c.values, 0, n.values[i-1])
+	if !c.leaf() {
+		c.children = slices.Insert(c.children, 0, s.children[last+1])
+		s.children = slices.Delete(s.children, last+1, last+2)
+	}
+
+	n.keys[i-1], n.values[i-1] = s.keys[last], s.values[last]
+	s.keys = slices.Delete(s.keys, last, last+1)
+	s.values = slices.Delete(s.values, last, last+1)
+
+	c.recount()
+	s.recount()
+}
+
+// internal function to rotate the first key of child i+1 through n into
+// child i
+func (t *BTree[K, V]) borrowFromNext(n *node[K, V], i int) {
+	c, s := n.children[i], n.children[i+1]
+
+	c.keys = append(c.keys, n.keys[i])
+	c.values = append(c.values, n.values[i])
+	if !c.leaf() {
+		c.children = append(c.children, s.children[0])
+		s.children = slices.Delete(s.children, 0, 1)
+	}
+
+	n.keys[i], n.values[i] = s.keys[0], s.values[0]
+	s.keys = slices.Delete(s.keys, 0, 1)
+	s.values = slices.Delete(s.values, 0, 1)
+
+	c.recount()
+	s.recount()
+}
+
+// internal function to merge child i+1 and the key i of n into child i
+func (t *BTree[K, V]) merge(n *node[K, V], i int) {
+	c, s := n.children[i], n.children[i+1]
+
+	c.keys = append(append(c.keys, n.keys[i]), s.keys...)
+	c.values = append(append(c.values, n.values[i]), s.values...)
+	c.children = append(c.children, s.children...)
+
+	n.keys = slices.Delete(n.keys, i, i+1)
+	n.values = slices.Delete(n.values, i, i+1)
+	n.children = slices.Delete(n.children, i+1, i+2)
+
+	c.recount()
+}
+
+// internal recursive function to iterate in order, it returns false if the
+// iteration was stopped
+func ascend[K cmp.Ordered, V any](n *node[K, V], yield func(K, V) bool) bool {
+	if n == nil {
+		return true
+	}
+
+	for i := range n.keys {
+		if !n.leaf() && !ascend(n.children[i], yield) {
+			return false
+		}
+		if !yield(n.keys[i], n.values[i]) {
+			return false
+		}
+	}
+
+	if !n.leaf() {
+		return ascend(n.children[len(n.keys)], yield)
+	}
+	return true
+}
+
+// internal recursive function to visit a range, it returns false if the
+// iteration was stopped
+func rangeNodes[K cmp.Ordered, V any](n *node[K, V], lo, hi K, f func(K, V) bool) bool {
+	if n == nil {
+		return true
+	}
+
+	// Skip the keys and children before lo.
+	i, _ := slices.BinarySearch(n.keys, lo)
+	for ; i < len(n.keys); i++ {
+		if !n.leaf() && !rangeNodes(n.children[i], lo, hi, f) {
+			return false
+		}
+
+		if n.keys[i] > hi {
+			return false
+		}
+
+		if !f(n.keys[i], n.values[i]) {
+			return false
+		}
+	}
+
+	if !n.leaf() {
+		return rangeNodes(n.children[len(n.keys)], lo, hi, f)
+	}
+	return true
+}
+
+// Validate checks the invariants of the tree: sorted keys, number of keys
+// per node, all leaves at the same depth and the sizes kept in every node.
+// It returns nil if the tree is sound.
+func (t *BTree[K, V]) Validate() error {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	if t.root == nil {
+		return nil
+	}
+
+	_, err := t.validate(t.root, nil, nil, true)
+	return err
+}
+
+// internal recursive function to check a subtree whose keys must lie in
+// (lo, hi)(nil means unbounded), it returns the depth of its leaves
+func (t *BTree[K, V]) validate(n *node[K, V], lo, hi *K, root bool) (int, error) {
+	if len(n.keys) > t.maxKeys() || (!root && len(n.keys) < t.degree-1) || len(n.keys) == 0 {
+		return 0, fmt.Errorf("btree: node %v holds %d keys", n.keys, len(n.keys))
+	}
+
+	if len(n.values) != len(n.keys) {
+		return 0, fmt.Errorf("btree: node %v holds %d values", n.keys, len(n.values))
+	}
+
+	for i, k := range n.keys {
+		if (i > 0 && k <= n.keys[i-1]) || (lo != nil && k <= *lo) || (hi != nil && k >= *hi) {
+			return 0, fmt.Errorf("btree: key %v is out of order", k)
+		}
+	}
+
+	if n.leaf() {
+		if n.size != len(n.keys) {
+			return 0, fmt.Errorf("btree: node %v has size %d, should be %d", n.keys, n.size, len(n.keys))
+		}
+		return 1, nil
+	}
+
+	if len(n.children) != len(n.keys)+1 {
+		return 0, fmt.Errorf("btree: node %v has %d children", n.keys, len(n.children))
+	}
+
+	depth, s := -1, len(n.keys)
+	for i, c := range n.children {
+		clo, chi := lo, hi
+		if i > 0 {
+			clo = &n.keys[i-1]
+		}
+		if i < len(n.keys) {
+			chi = &n.keys[i]
+		}
+
+		d, err := t.validate(c, clo, chi, false)
+		if err != nil {
+			return 0, err
+		}
+
+		if depth >= 0 && d != depth {
+			return 0, fmt.Errorf("btree: leaves of node %v are not at the same depth", n.keys)
+		}
+		depth = d
+		s += c.size
+	}
+
+	if n.size != s {
+		return 0, fmt.Errorf("btree: node %v has size %d, should be %d", n.keys, n.size, s)
+	}
+	return depth + 1, nil
+}
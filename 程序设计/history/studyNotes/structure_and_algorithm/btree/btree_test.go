@@ -0,0 +1,160 @@
+package btree
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	if _, err := New[int, int](1); err != ErrInvalidDegree {
+		t.Errorf("New(1) should return ErrInvalidDegree, got %v", err)
+	}
+
+	tr, err := New[string, int](2)
+	if err != nil {
+		t.Fatalf("New(2) failed: %v", err)
+	}
+
+	if _, ok := tr.Get("a"); ok || tr.Len() != 0 {
+		t.Errorf("new tree should be empty")
+	}
+
+	if tr.Delete("a") {
+		t.Errorf("Delete on an empty tree should return false")
+	}
+}
+
+func TestZeroValue(t *testing.T) {
+	var tr BTree[int, int]
+	for k := 0; k < 1000; k++ {
+		tr.Put(k, k)
+	}
+
+	if tr.Len() != 1000 || tr.degree != DefaultDegree {
+		t.Errorf("the zero value should hold 1000 keys with degree %d, got %d keys, degree %d", DefaultDegree, tr.Len(), tr.degree)
+	}
+	if err := tr.Validate(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestOrderedMap(t *testing.T) {
+	for _, degree := range []int{2, 3, 4, DefaultDegree} {
+		t.Run(fmt.Sprintf("degree=%d", degree), func(t *testing.T) {
+			tr, _ := New[int, int](degree)
+			ref := map[int]int{}
+			r := rand.New(rand.NewSource(int64(degree)))
+
+			for i := 0; i < 5000; i++ {
+				key := r.Intn(500)
+
+				switch r.Intn(3) {
+				case 0, 1:
+					tr.Put(key, i)
+					ref[key] = i
+				case 2:
+					ok := tr.Delete(key)
+					if _, rok := ref[key]; ok != rok {
+						t.Fatalf("Delete(%d) should return %v, got %v", key, rok, ok)
+					}
+					delete(ref, key)
+				}
+
+				if err := tr.Validate(); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			keys := make([]int, 0, len(ref))
+			for k := range ref {
+				keys = append(keys, k)
+			}
+			slices.Sort(keys)
+
+			if tr.Len() != len(keys) {
+				t.Fatalf("Len should be %d, got %d", len(keys), tr.Len())
+			}
+
+			i := 0
+			for k, v := range tr.All() {
+				if k != keys[i] || v != ref[k] {
+					t.Fatalf("key %d should be %d, got %d", i, keys[i], k)
+				}
+				i++
+			}
+
+			for i, k := range keys {
+				if v, ok := tr.Get(k); !ok || v != ref[k] {
+					t.Fatalf("Get(%d) should return %d, got %d, %v", k, ref[k], v, ok)
+				}
+
+				if sk, sv, ok := tr.Select(i); !ok || sk != k || sv != ref[k] {
+					t.Fatalf("Select(%d) should be %d, got %d, %v", i, k, sk, ok)
+				}
+
+				if r := tr.Rank(k); r != i {
+					t.Fatalf("Rank(%d) should be %d, got %d", k, i, r)
+				}
+
+				if r := tr.Rank(k + 1); r != i+1 {
+					t.Fatalf("Rank(%d) should be %d, got %d", k+1, i+1, r)
+				}
+			}
+
+			if _, _, ok := tr.Select(len(keys)); ok {
+				t.Errorf("Select(%d) should not be found", len(keys))
+			}
+
+			// Delete everything, the tree shrinks back to empty.
+			for _, k := range keys {
+				if !tr.Delete(k) {
+					t.Fatalf("Delete(%d) should succeed", k)
+				}
+			}
+
+			if tr.Len() != 0 || tr.root != nil {
+				t.Errorf("tree should be empty, got Len %d", tr.Len())
+			}
+		})
+	}
+}
+
+func TestRange(t *testing.T) {
+	tr, _ := New[int, string](2)
+	for i := 0; i < 100; i++ {
+		tr.Put(i*2, fmt.Sprint(i*2))
+	}
+
+	var got []int
+	tr.Range(9, 21, func(k int, v string) bool {
+		if v != fmt.Sprint(k) {
+			t.Errorf("value of %d should be %q, got %q", k, fmt.Sprint(k), v)
+		}
+		got = append(got, k)
+		return true
+	})
+	if fmt.Sprint(got) != "[10 12 14 16 18 20]" {
+		t.Errorf("Range(9, 21) should be [10 12 14 16 18 20], got %v", got)
+	}
+
+	got = got[:0]
+	tr.Range(0, 1000, func(k int, v string) bool {
+		got = append(got, k)
+		return len(got) < 3
+	})
+	if fmt.Sprint(got) != "[0 2 4]" {
+		t.Errorf("Range should stop after 3 keys, got %v", got)
+	}
+
+	got = got[:0]
+	for k := range tr.All() {
+		if got = append(got, k); len(got) == 2 {
+			break
+		}
+	}
+	if fmt.Sprint(got) != "[0 2]" {
+		t.Errorf("All should stop after 2 keys, got %v", got)
+	}
+}
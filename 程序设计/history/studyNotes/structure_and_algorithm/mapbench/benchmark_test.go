@@ -0,0 +1,133 @@
+package mapbench
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"testing"
+
+	"structure_and_algorithm/binarysearchtree"
+	"structure_and_algorithm/btree"
+	"structure_and_algorithm/skiplist"
+)
+
+// orderedMap the ordered map API shared by the compared data structures
+type orderedMap interface {
+	Get(key int) (int, bool)
+	Put(key, value int)
+	Delete(key int) bool
+	Range(lo, hi int, f func(int, int) bool)
+}
+
+// maps the compared data structures, the sub-benchmarks are named
+// map=<name> so that benchstat can compare them with -col /map
+var maps = []struct {
+	name string
+	new  func() orderedMap
+}{
+	{"bst", func() orderedMap { return binarysearchtree.NewAVLTree[int, int]() }},
+	{"btree", func() orderedMap { return new(btree.BTree[int, int]) }},
+	{"skiplist", func() orderedMap { return new(skiplist.SkipList[int, int]) }},
+}
+
+var benchSizes = []int{1000, 100000, 1000000}
+
+// heapInUse returns the bytes of live heap objects
+func heapInUse() uint64 {
+	var ms runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&ms)
+	return ms.HeapAlloc
+}
+
+// buildMap returns a map filled with keys in random order, and the heap
+// bytes it uses per key
+func buildMap(newMap func() orderedMap, n int) (orderedMap, float64) {
+	before := heapInUse()
+
+	m := newMap()
+	for _, k := range rand.New(rand.NewSource(1)).Perm(n) {
+		m.Put(k, k)
+	}
+
+	after := heapInUse()
+	return m, float64(int64(after)-int64(before)) / float64(n)
+}
+
+func BenchmarkOrderedMap(b *testing.B) {
+	for _, impl := range maps {
+		b.Run("map="+impl.name, func(b *testing.B) {
+			benchmarkOrderedMap(b, impl.new)
+		})
+	}
+}
+
+func benchmarkOrderedMap(b *testing.B, newMap func() orderedMap) {
+	for _, n := range benchSizes {
+		keys := rand.New(rand.NewSource(2)).Perm(n)
+
+		b.Run(fmt.Sprintf("Put/n=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+
+			var m orderedMap
+			for i := 0; i < b.N; i++ {
+				if i%n == 0 {
+					m = newMap()
+				}
+				m.Put(keys[i%n], i)
+			}
+		})
+
+		m, perKey := buildMap(newMap, n)
+
+		b.Run(fmt.Sprintf("Get/n=%d", n), func(b *testing.B) {
+			b.ReportMetric(perKey, "heap-B/key")
+			for i := 0; i < b.N; i++ {
+				m.Get(keys[i%n])
+			}
+		})
+
+		b.Run(fmt.Sprintf("Range100/n=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				lo := keys[i%n]
+				m.Range(lo, lo+100, func(int, int) bool { return true })
+			}
+		})
+
+		b.Run(fmt.Sprintf("Delete/n=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				k := keys[i%n]
+				m.Delete(k)
+				m.Put(k, k)
+			}
+		})
+	}
+}
+
+// BenchmarkConcurrentMap runs a mix of Get and Put on all the procs, with
+// writes percent of the operations being a Put. Run it with -cpu 1,2,4,8 to
+// see how it scales.
+func BenchmarkConcurrentMap(b *testing.B) {
+	const n = 100000
+
+	for _, impl := range maps {
+		for _, writes := range []int{0, 10, 50, 100} {
+			b.Run(fmt.Sprintf("map=%s/writes=%d%%", impl.name, writes), func(b *testing.B) {
+				m, _ := buildMap(impl.new, n)
+
+				b.ResetTimer()
+				b.RunParallel(func(pb *testing.PB) {
+					r := rand.New(rand.NewSource(rand.Int63()))
+					for pb.Next() {
+						k := r.Intn(n)
+						if r.Intn(100) < writes {
+							m.Put(k, k)
+						} else {
+							m.Get(k)
+						}
+					}
+				})
+			})
+		}
+	}
+}
@@ -0,0 +1,4 @@
+// Package mapbench compares the ordered maps of structure_and_algorithm,
+// binarysearchtree.Tree, btree.BTree and skiplist.SkipList, with the same
+// benchmarks. It has no code, see benchmark_test.go.
+package mapbench
@@ -0,0 +1,332 @@
+package skiplist
+
+import (
+	"cmp"
+	"fmt"
+	"iter"
+	"math/bits"
+	"math/rand/v2"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// maxLevel is enough for 4^maxLevel keys.
+	maxLevel = 24
+)
+
+// node a skip list node linked at levels [0, topLevel)
+type node[K cmp.Ordered, V any] struct {
+	key   K
+	value atomic.Pointer[V]
+	next  []atomic.Pointer[node[K, V]]
+
+	// lock guards the links from this node while it is a predecessor, and
+	// the node itself while it is removed
+	lock sync.Mutex
+
+	// marked is set when the node is logically removed
+	marked atomic.Bool
+
+	// fullyLinked is set once the node is linked at all its levels
+	fullyLinked atomic.Bool
+}
+
+func (n *node[K, V]) topLevel() int {
+	return len(n.next)
+}
+
+// SkipList the concurrent skip list(Thread safe), an ordered map whose
+// writers only lock the few nodes around the key they change, and whose
+// readers never lock. It implements the lazy skip list of Herlihy et al.
+// The zero value is an empty skip list.
+type SkipList[K cmp.Ordered, V any] struct {
+	head node[K, V]
+	once sync.Once
+
+	// number of keys in the list
+	count atomic.Int64
+}
+
+// init links the head sentinel at all the levels
+func (s *SkipList[K, V]) init() {
+	s.once.Do(func() {
+		s.head.next = make([]atomic.Pointer[node[K, V]], maxLevel)
+		s.head.fullyLinked.Store(true)
+	})
+}
+
+// randomLevel returns a level from 1 to maxLevel, level l having a
+// probability of (1/4)^(l-1)
+func randomLevel() int {
+	return min(1+bits.TrailingZeros64(rand.Uint64())/2, maxLevel)
+}
+
+// find fills the predecessors and successors of key at every level, it
+// returns the highest level where key was found, or -1
+func (s *SkipList[K, V]) find(key K, preds, succs []*node[K, V]) int {
+	found := -1
+	pred := &s.head
+	for level := maxLevel - 1; level >= 0; level-- {
+		curr := pred.next[level].Load()
+		for curr != nil && curr.key < key {
+			pred, curr = curr, curr.next[level].Load()
+		}
+
+		if found == -1 && curr != nil && curr.key == key {
+			found = level
+		}
+		preds[level], succs[level] = pred, curr
+	}
+	return found
+}
+
+// Get returns the value stored with key `key`, it never blocks
+func (s *SkipList[K, V]) Get(key K) (V, bool) {
+	s.init()
+
+	pred := &s.head
+	for level := maxLevel - 1; level >= 0; level-- {
+		curr := pred.next[level].Load()
+		for curr != nil && curr.key < key {
+			pred, curr = curr, curr.next[level].Load()
+		}
+
+		if curr != nil && curr.key == key {
+			if curr.fullyLinked.Load() && !curr.marked.Load() {
+				return *curr.value.Load(), true
+			}
+			break
+		}
+	}
+
+	var zero V
+	return zero, false
+}
+
+// Put stores the value with key `key`, replacing the old value if the key
+// already exists in the list
+func (s *SkipList[K, V]) Put(key K, value V) {
+	s.init()
+
+	var preds, succs [maxLevel]*node[K, V]
+	topLevel := randomLevel()
+
+	for {
+		if found := s.find(key, preds[:], succs[:]); found != -1 {
+			n := succs[found]
+			if !n.marked.Load() {
+				// Wait for a concurrent Put of the same key to finish.
+				for !n.fullyLinked.Load() {
+					runtime.Gosched()
+				}
+				n.value.Store(&value)
+				return
+			}
+
+			// Being removed, retry once it is unlinked.
+			continue
+		}
+
+		// Lock the predecessors and check they still link to the
+		// successors, the same node may be the predecessor at many levels.
+		highestLocked, valid := -1, true
+		var prev *node[K, V]
+		for level := 0; valid && level < topLevel; level++ {
+			pred, succ := preds[level], succs[level]
+			if pred != prev {
+				pred.lock.Lock()
+				highestLocked, prev = level, pred
+			}
+
+			valid = !pred.marked.Load() && (succ == nil || !succ.marked.Load()) && pred.next[level].Load() == succ
+		}
+
+		if !valid {
+			unlock(preds[:], highestLocked)
+			continue
+		}
+
+		n := &node[K, V]{key: key, next: make([]atomic.Pointer[node[K, V]], topLevel)}
+		n.value.Store(&value)
+		for level := 0; level < topLevel; level++ {
+			n.next[level].Store(succs[level])
+		}
+		for level := 0; level < topLevel; level++ {
+			preds[level].next[level].Store(n)
+		}
+		n.fullyLinked.Store(true)
+
+		unlock(preds[:], highestLocked)
+		s.count.Add(1)
+		return
+	}
+}
+
+// Delete removes the key `key` from the list, it returns false if the key
+// does not exist
+func (s *SkipList[K, V]) Delete(key K) bool {
+	s.init()
+
+	var (
+		preds, succs [maxLevel]*node[K, V]
+		victim       *node[K, V]
+	)
+
+	for {
+		found := s.find(key, preds[:], succs[:])
+
+		if victim == nil {
+			if found == -1 {
+				return false
+			}
+
+			// Only a node linked at all its levels can be removed, and it
+			// must be found at its top level.
+			n := succs[found]
+			if !n.fullyLinked.Load() || n.marked.Load() || n.topLevel()-1 != found {
+				return false
+			}
+
+			// Mark the node, it is then logically removed.
+			n.lock.Lock()
+			if n.marked.Load() {
+				n.lock.Unlock()
+				return false
+			}
+			n.marked.Store(true)
+			victim = n
+		}
+
+		highestLocked, valid := -1, true
+		var prev *node[K, V]
+		for level := 0; valid && level < victim.topLevel(); level++ {
+			pred := preds[level]
+			if pred != prev {
+				pred.lock.Lock()
+				highestLocked, prev = level, pred
+			}
+
+			valid = !pred.marked.Load() && pred.next[level].Load() == victim
+		}
+
+		if !valid {
+			unlock(preds[:], highestLocked)
+			continue
+		}
+
+		// Unlink the node from the top, readers walking it still find the
+		// rest of the list.
+		for level := victim.topLevel() - 1; level >= 0; level-- {
+			preds[level].next[level].Store(victim.next[level].Load())
+		}
+
+		victim.lock.Unlock()
+		unlock(preds[:], highestLocked)
+		s.count.Add(-1)
+		return true
+	}
+}
+
+// unlock unlocks the distinct predecessors locked up to highestLocked
+func unlock[K cmp.Ordered, V any](preds []*node[K, V], highestLocked int) {
+	var prev *node[K, V]
+	for level := 0; level <= highestLocked; level++ {
+		if preds[level] != prev {
+			preds[level].lock.Unlock()
+			prev = preds[level]
+		}
+	}
+}
+
+// Len returns the number of keys stored in the list
+func (s *SkipList[K, V]) Len() int {
+	return int(s.count.Load())
+}
+
+// All returns an iterator over the keys and values in ascending order. It
+// holds no lock, keys put or deleted during the iteration may or may not
+// be seen, so the loop body may modify the list.
+func (s *SkipList[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		s.init()
+
+		ascend(s.head.next[0].Load(), yield)
+	}
+}
+
+// Range visits in order the keys between lo and hi(both included), the
+// iteration stops as soon as f returns false. It holds no lock, see All.
+func (s *SkipList[K, V]) Range(lo, hi K, f func(K, V) bool) {
+	s.init()
+
+	// Find the predecessor of lo from the top level.
+	pred := &s.head
+	for level := maxLevel - 1; level >= 0; level-- {
+		for curr := pred.next[level].Load(); curr != nil && curr.key < lo; curr = curr.next[level].Load() {
+			pred = curr
+		}
+	}
+
+	ascend(pred.next[0].Load(), func(key K, value V) bool {
+		return key <= hi && f(key, value)
+	})
+}
+
+// ascend walks the bottom level from n, skipping the nodes being put or
+// deleted
+func ascend[K cmp.Ordered, V any](n *node[K, V], yield func(K, V) bool) {
+	for ; n != nil; n = n.next[0].Load() {
+		if !n.fullyLinked.Load() || n.marked.Load() {
+			continue
+		}
+
+		if !yield(n.key, *n.value.Load()) {
+			return
+		}
+	}
+}
+
+// Validate checks the list is sorted at every level, that every level is a
+// subset of the level below and that Len matches the number of keys. It
+// must not run concurrently with Put or Delete.
+func (s *SkipList[K, V]) Validate() error {
+	s.init()
+
+	count := 0
+	for level := maxLevel - 1; level >= 0; level-- {
+		below := s.head.next[0].Load()
+		if level > 0 {
+			below = s.head.next[level-1].Load()
+		}
+
+		var prev *node[K, V]
+		for n := s.head.next[level].Load(); n != nil; prev, n = n, n.next[level].Load() {
+			if prev != nil && prev.key >= n.key {
+				return fmt.Errorf("skiplist: key %v after %v at level %d", n.key, prev.key, level)
+			}
+
+			if n.marked.Load() || !n.fullyLinked.Load() || n.topLevel() <= level {
+				return fmt.Errorf("skiplist: key %v wrongly linked at level %d", n.key, level)
+			}
+
+			if level == 0 {
+				count++
+				continue
+			}
+
+			for below != nil && below != n {
+				below = below.next[level-1].Load()
+			}
+			if below == nil {
+				return fmt.Errorf("skiplist: key %v at level %d is missing below", n.key, level)
+			}
+		}
+	}
+
+	if count != s.Len() {
+		return fmt.Errorf("skiplist: Len is %d, %d keys linked", s.Len(), count)
+	}
+	return nil
+}
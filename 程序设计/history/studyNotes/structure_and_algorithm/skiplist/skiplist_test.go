@@ -0,0 +1,224 @@
+package skiplist
+
+import (
+	"math/rand"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestEmpty(t *testing.T) {
+	var s SkipList[string, int]
+
+	if _, ok := s.Get("a"); ok || s.Len() != 0 {
+		t.Errorf("zero value should be an empty list")
+	}
+
+	if s.Delete("a") {
+		t.Errorf("Delete on an empty list should return false")
+	}
+
+	for k := range s.All() {
+		t.Errorf("All on an empty list should not yield, got %s", k)
+	}
+
+	if err := s.Validate(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestOrderedMap(t *testing.T) {
+	var s SkipList[int, int]
+	ref := map[int]int{}
+	r := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 5000; i++ {
+		key := r.Intn(500)
+
+		switch r.Intn(3) {
+		case 0, 1:
+			s.Put(key, i)
+			ref[key] = i
+		case 2:
+			ok := s.Delete(key)
+			if _, rok := ref[key]; ok != rok {
+				t.Fatalf("Delete(%d) should return %v, got %v", key, rok, ok)
+			}
+			delete(ref, key)
+		}
+	}
+
+	if err := s.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	keys := make([]int, 0, len(ref))
+	for k := range ref {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	if s.Len() != len(keys) {
+		t.Fatalf("Len should be %d, got %d", len(keys), s.Len())
+	}
+
+	i := 0
+	for k, v := range s.All() {
+		if k != keys[i] || v != ref[k] {
+			t.Fatalf("key %d should be %d, got %d", i, keys[i], k)
+		}
+		i++
+	}
+
+	for _, k := range keys {
+		if v, ok := s.Get(k); !ok || v != ref[k] {
+			t.Fatalf("Get(%d) should return %d, got %d, %v", k, ref[k], v, ok)
+		}
+	}
+}
+
+func TestRange(t *testing.T) {
+	var s SkipList[int, int]
+	for k := 0; k < 100; k += 2 {
+		s.Put(k, k)
+	}
+
+	var got []int
+	s.Range(9, 21, func(k, _ int) bool {
+		got = append(got, k)
+		return true
+	})
+	if want := []int{10, 12, 14, 16, 18, 20}; !slices.Equal(got, want) {
+		t.Errorf("Range(9, 21) should visit %v, got %v", want, got)
+	}
+
+	got = got[:0]
+	s.Range(10, 100, func(k, _ int) bool {
+		got = append(got, k)
+		return len(got) < 3
+	})
+	if want := []int{10, 12, 14}; !slices.Equal(got, want) {
+		t.Errorf("Range should stop when f returns false, got %v", got)
+	}
+
+	s.Range(200, 300, func(k, _ int) bool {
+		t.Errorf("Range(200, 300) should not visit %d", k)
+		return true
+	})
+}
+
+func TestModifyWhileIterating(t *testing.T) {
+	var s SkipList[int, int]
+	for k := 0; k < 100; k++ {
+		s.Put(k, k)
+	}
+
+	// The iteration holds no lock, the loop body may delete keys.
+	for k := range s.All() {
+		if k%2 == 1 {
+			s.Delete(k)
+		}
+	}
+
+	if s.Len() != 50 {
+		t.Errorf("Len should be 50, got %d", s.Len())
+	}
+
+	if err := s.Validate(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestConcurrent(t *testing.T) {
+	const (
+		workers = 8
+		keys    = 1000
+	)
+
+	var (
+		s  SkipList[int, int]
+		wg sync.WaitGroup
+	)
+
+	// Every worker puts and deletes the same keys, then puts its own keys.
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+
+			r := rand.New(rand.NewSource(int64(w)))
+			for i := 0; i < 10000; i++ {
+				key := r.Intn(keys)
+				switch r.Intn(3) {
+				case 0:
+					s.Put(key, w)
+				case 1:
+					s.Delete(key)
+				case 2:
+					if v, ok := s.Get(key); ok && (v < 0 || v >= workers) {
+						t.Errorf("Get(%d) returned a value never put: %d", key, v)
+					}
+				}
+			}
+
+			for k := keys + w; k < 2*keys; k += workers {
+				s.Put(k, w)
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	if err := s.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	prev := -1
+	for k, v := range s.All() {
+		if k <= prev {
+			t.Fatalf("key %d after %d", k, prev)
+		}
+		if k >= keys && v != (k-keys)%workers {
+			t.Fatalf("key %d should hold %d, got %d", k, (k-keys)%workers, v)
+		}
+		prev = k
+	}
+
+	for k := keys; k < 2*keys; k++ {
+		if _, ok := s.Get(k); !ok {
+			t.Fatalf("Get(%d) should find the key", k)
+		}
+	}
+}
+
+func TestConcurrentDeleteOnce(t *testing.T) {
+	var s SkipList[int, int]
+	for k := 0; k < 1000; k++ {
+		s.Put(k, k)
+	}
+
+	// Each key must be deleted by exactly one worker.
+	var (
+		wg      sync.WaitGroup
+		deleted [4]int
+	)
+	for w := range deleted {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for k := 0; k < 1000; k++ {
+				if s.Delete(k) {
+					deleted[w]++
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	total := 0
+	for _, d := range deleted {
+		total += d
+	}
+	if total != 1000 || s.Len() != 0 {
+		t.Errorf("1000 keys should be deleted once, got %d deletes, Len %d", total, s.Len())
+	}
+}
@@ -1,32 +1,172 @@
 package chan_concurrent
 
-func Concurrent(MaxCG int64) {
-    ch := make(chan struct{}, MaxCG)
-    
-    for i := 0; i < MaxCG; i++ {
-        ch <- struct{}{}
+import (
+    "container/list"
+    "context"
+    "sync"
+)
+
+// Weighted the weighted semaphore, it bounds the total weight of the
+// tokens held at the same time. Waiters are served in FIFO order: a large
+// request blocks the smaller ones queued after it, so it can't starve.
+type Weighted struct {
+    size int64
+    cur  int64
+    mu   sync.Mutex
+
+    // waiters blocked in Acquire, in arrival order
+    waiters list.List
+}
+
+// waiter a blocked Acquire, ready is closed once its tokens are acquired
+type waiter struct {
+    n     int64
+    ready chan struct{}
+}
+
+// NewWeighted returns a semaphore of total weight n
+func NewWeighted(n int64) *Weighted {
+    return &Weighted{size: n}
+}
+
+// Acquire acquires n tokens, blocking until they are available or ctx is
+// done. On failure it returns ctx.Err() and acquires nothing.
+func (s *Weighted) Acquire(ctx context.Context, n int64) error {
+    if err := ctx.Err(); err != nil {
+        return err
     }
 
-    done := make(chan bool)
-    waitAllJobs := make(chan bool)
-    go func(){
-        for {
-            <-done
+    s.mu.Lock()
+    if s.size-s.cur >= n && s.waiters.Len() == 0 {
+        s.cur += n
+        s.mu.Unlock()
+        return nil
+    }
 
-            ch <- struct{}{}
+    if n > s.size {
+        // The request can never be served, don't block the others.
+        s.mu.Unlock()
+        <-ctx.Done()
+        return ctx.Err()
+    }
+
+    ready := make(chan struct{})
+    elem := s.waiters.PushBack(waiter{n: n, ready: ready})
+    s.mu.Unlock()
+
+    select {
+    case <-ready:
+        return nil
+    case <-ctx.Done():
+        s.mu.Lock()
+        select {
+        case <-ready:
+            // Acquired after ctx was done, give the tokens back.
+            s.cur -= n
+            s.notifyWaiters()
+        default:
+            // The waiters behind may fit now that this one gives up.
+            front := s.waiters.Front() == elem
+            s.waiters.Remove(elem)
+            if front {
+                s.notifyWaiters()
+            }
         }
-        waitAllJobs <- true
-    }()
+        s.mu.Unlock()
+        return ctx.Err()
+    }
+}
+
+// TryAcquire acquires n tokens without blocking, it returns false and
+// acquires nothing if they are not available
+func (s *Weighted) TryAcquire(n int64) bool {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    if s.size-s.cur >= n && s.waiters.Len() == 0 {
+        s.cur += n
+        return true
+    }
+    return false
+}
+
+// Release releases n tokens, it panics if more tokens are released than
+// held
+func (s *Weighted) Release(n int64) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
 
+    s.cur -= n
+    if s.cur < 0 {
+        panic("chan_concurrent: released more than held")
+    }
+    s.notifyWaiters()
+}
+
+// internal function to wake the waiters at the front of the queue whose
+// tokens are available, the caller must hold the lock
+func (s *Weighted) notifyWaiters() {
     for {
-        <-ch 
+        front := s.waiters.Front()
+        if front == nil {
+            return
+        }
+
+        w := front.Value.(waiter)
+        if s.size-s.cur < w.n {
+            // FIFO: the waiters behind must not overtake this one.
+            return
+        }
+
+        s.cur += w.n
+        s.waiters.Remove(front)
+        close(w.ready)
+    }
+}
+
+// ForEach calls fn for every item with at most limit calls running at the
+// same time(no limit if limit <= 0). It stops at the first error, cancels
+// the context passed to the running calls, waits for them and returns the
+// error.
+func ForEach[T any](ctx context.Context, items []T, limit int, fn func(context.Context, T) error) error {
+    if limit <= 0 {
+        limit = len(items)
+    }
+
+    ctx, cancel := context.WithCancel(ctx)
+    defer cancel()
+
+    var (
+        sem   = NewWeighted(int64(limit))
+        wg    sync.WaitGroup
+        once  sync.Once
+        first error
+    )
+
+    fail := func(err error) {
+        once.Do(func() {
+            first = err
+            cancel()
+        })
+    }
+
+    for _, item := range items {
+        if err := sem.Acquire(ctx, 1); err != nil {
+            fail(err)
+            break
+        }
+
+        wg.Add(1)
+        go func(item T) {
+            defer wg.Done()
+            defer sem.Release(1)
 
-        go func() {
-            // Todo something
-            
-            done <- true
-        }()
+            if err := fn(ctx, item); err != nil {
+                fail(err)
+            }
+        }(item)
     }
 
-    <-waitAllJobs
+    wg.Wait()
+    return first
 }
@@ -0,0 +1,213 @@
+package chan_concurrent
+
+import (
+    "context"
+    "errors"
+    "sync/atomic"
+    "testing"
+    "time"
+)
+
+func TestWeighted(t *testing.T) {
+    s := NewWeighted(3)
+
+    if err := s.Acquire(context.Background(), 2); err != nil {
+        t.Fatalf("Acquire(2) should succeed, got %v", err)
+    }
+    if s.TryAcquire(2) {
+        t.Errorf("TryAcquire(2) should fail with 1 token left")
+    }
+    if !s.TryAcquire(1) {
+        t.Errorf("TryAcquire(1) should succeed with 1 token left")
+    }
+
+    ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+    defer cancel()
+    if err := s.Acquire(ctx, 1); err != context.DeadlineExceeded {
+        t.Errorf("Acquire on a full semaphore should time out, got %v", err)
+    }
+
+    s.Release(3)
+    if !s.TryAcquire(3) {
+        t.Errorf("TryAcquire(3) should succeed once all released")
+    }
+}
+
+func TestWeightedTooLarge(t *testing.T) {
+    s := NewWeighted(1)
+
+    ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+    defer cancel()
+    if err := s.Acquire(ctx, 2); err != context.DeadlineExceeded {
+        t.Errorf("Acquire more than the size should block until ctx is done, got %v", err)
+    }
+
+    // It didn't block the others.
+    if !s.TryAcquire(1) {
+        t.Errorf("TryAcquire(1) should succeed")
+    }
+}
+
+func TestWeightedReleasePanics(t *testing.T) {
+    defer func() {
+        if recover() == nil {
+            t.Errorf("Release more than held should panic")
+        }
+    }()
+
+    NewWeighted(1).Release(1)
+}
+
+// waitQueued waits until the semaphore has n waiters
+func waitQueued(s *Weighted, n int) {
+    for {
+        s.mu.Lock()
+        l := s.waiters.Len()
+        s.mu.Unlock()
+
+        if l == n {
+            return
+        }
+        time.Sleep(time.Millisecond)
+    }
+}
+
+func TestWeightedFIFO(t *testing.T) {
+    s := NewWeighted(4)
+    s.Acquire(context.Background(), 4)
+
+    // The large request queued first is served before the small ones.
+    acquired := make(chan int64, 3)
+    for i, n := range []int64{3, 1, 1} {
+        go func(n int64) {
+            s.Acquire(context.Background(), n)
+            acquired <- n
+        }(n)
+        waitQueued(s, i+1)
+    }
+
+    if s.TryAcquire(1) {
+        t.Errorf("TryAcquire should not overtake the waiters")
+    }
+
+    s.Release(2)
+    time.Sleep(10 * time.Millisecond)
+    if len(acquired) != 0 {
+        t.Errorf("the small requests should wait behind the large one")
+    }
+
+    // 3 then 1 fit, the last 1 waits.
+    s.Release(2)
+    waitQueued(s, 1)
+    if a, b := <-acquired, <-acquired; a+b != 4 {
+        t.Errorf("3 and 1 should be acquired, got %d and %d", a, b)
+    }
+
+    s.Release(1)
+    if n := <-acquired; n != 1 {
+        t.Errorf("the last waiter should acquire 1, got %d", n)
+    }
+}
+
+func TestWeightedCancelFront(t *testing.T) {
+    s := NewWeighted(2)
+    s.Acquire(context.Background(), 1)
+
+    // The waiter at the front gives up, the one behind fits.
+    ctx, cancel := context.WithCancel(context.Background())
+    errc := make(chan error)
+    go func() {
+        errc <- s.Acquire(ctx, 2)
+    }()
+    waitQueued(s, 1)
+
+    done := make(chan error)
+    go func() {
+        done <- s.Acquire(context.Background(), 1)
+    }()
+    waitQueued(s, 2)
+
+    cancel()
+    if err := <-errc; err != context.Canceled {
+        t.Errorf("the canceled Acquire should fail, got %v", err)
+    }
+    if err := <-done; err != nil {
+        t.Errorf("the waiter behind should get its token, got %v", err)
+    }
+}
+
+func TestForEach(t *testing.T) {
+    items := make([]int, 100)
+    for i := range items {
+        items[i] = i
+    }
+
+    var (
+        running, peak atomic.Int32
+        sum           atomic.Int64
+    )
+    err := ForEach(context.Background(), items, 4, func(_ context.Context, i int) error {
+        n := running.Add(1)
+        defer running.Add(-1)
+
+        for {
+            p := peak.Load()
+            if n <= p || peak.CompareAndSwap(p, n) {
+                break
+            }
+        }
+
+        time.Sleep(time.Millisecond)
+        sum.Add(int64(i))
+        return nil
+    })
+
+    if err != nil {
+        t.Fatalf("ForEach should succeed, got %v", err)
+    }
+    if sum.Load() != 4950 {
+        t.Errorf("every item should be processed, sum is %d", sum.Load())
+    }
+    if peak.Load() > 4 {
+        t.Errorf("at most 4 calls should run at once, got %d", peak.Load())
+    }
+}
+
+func TestForEachError(t *testing.T) {
+    errBoom := errors.New("boom")
+    items := make([]int, 100)
+    for i := range items {
+        items[i] = i
+    }
+
+    var calls atomic.Int32
+    err := ForEach(context.Background(), items, 2, func(ctx context.Context, i int) error {
+        calls.Add(1)
+        if i == 1 {
+            return errBoom
+        }
+
+        <-ctx.Done()
+        return ctx.Err()
+    })
+
+    if err != errBoom {
+        t.Errorf("ForEach should return the first error, got %v", err)
+    }
+    if calls.Load() >= 100 {
+        t.Errorf("ForEach should stop at the first error, got %d calls", calls.Load())
+    }
+}
+
+func TestForEachCanceled(t *testing.T) {
+    ctx, cancel := context.WithCancel(context.Background())
+    cancel()
+
+    err := ForEach(ctx, []int{1, 2, 3}, 1, func(context.Context, int) error {
+        t.Errorf("fn should not be called with a canceled context")
+        return nil
+    })
+    if err != context.Canceled {
+        t.Errorf("ForEach should return context.Canceled, got %v", err)
+    }
+}
@@ -0,0 +1,216 @@
+//go:build linux
+
+package synchronous
+
+import (
+    "context"
+    "errors"
+    "io"
+    "os"
+    "path/filepath"
+    "strconv"
+    "strings"
+    "sync"
+    "syscall"
+    "time"
+)
+
+// filePollInterval how often Acquire retries a lock held by another
+// process, flock(2) can't wait with a timeout
+const filePollInterval = 10 * time.Millisecond
+
+// FileLocker the cross-process Locker based on flock(2), the lock `name`
+// is the file name.lock in the directory of the locker, and the file
+// stores the last fencing token. The kernel releases the lock when its
+// holder exits, even if it crashes.
+//
+// Unlike MemoryLocker the lease is enforced by a timer in the holder
+// process, other processes can't take over an expired lease: a holder
+// which is stopped (SIGSTOP, a frozen VM) keeps the lock until it resumes
+// or exits. The fencing token still protects the resource from it.
+type FileLocker struct {
+    dir string
+
+    mu   sync.Mutex
+    held map[string]*fileLease
+}
+
+// fileLease the lock file held by a lease
+type fileLease struct {
+    token uint64
+    file  *os.File
+
+    // timer releases the lock when the lease expires
+    timer *time.Timer
+}
+
+// NewFileLocker returns a Locker whose lock files are in dir, the directory
+// is created if needed
+func NewFileLocker(dir string) (*FileLocker, error) {
+    if err := os.MkdirAll(dir, 0755); err != nil {
+        return nil, err
+    }
+
+    return &FileLocker{dir: dir, held: make(map[string]*fileLease)}, nil
+}
+
+// Acquire acquires the lock `name` for ttl, see Locker. It polls a lock
+// held by another process.
+func (f *FileLocker) Acquire(ctx context.Context, name string, ttl time.Duration) (*Lease, error) {
+    if err := checkLock(name, ttl); err != nil {
+        return nil, err
+    }
+
+    for {
+        if err := ctx.Err(); err != nil {
+            return nil, err
+        }
+
+        lease, err := f.tryAcquire(name, ttl)
+        if err != ErrLocked {
+            return lease, err
+        }
+
+        timer := time.NewTimer(filePollInterval)
+        select {
+        case <-timer.C:
+        case <-ctx.Done():
+            timer.Stop()
+        }
+    }
+}
+
+// TryAcquire acquires the lock `name` for ttl without blocking, see Locker
+func (f *FileLocker) TryAcquire(name string, ttl time.Duration) (*Lease, error) {
+    if err := checkLock(name, ttl); err != nil {
+        return nil, err
+    }
+    return f.tryAcquire(name, ttl)
+}
+
+// internal function to lock the file of `name` and increment its token
+func (f *FileLocker) tryAcquire(name string, ttl time.Duration) (*Lease, error) {
+    file, err := os.OpenFile(filepath.Join(f.dir, name+".lock"), os.O_RDWR|os.O_CREATE, 0644)
+    if err != nil {
+        return nil, err
+    }
+
+    // Every open file has its own lock, so the goroutines of this process
+    // exclude each other too.
+    if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+        file.Close()
+        if errors.Is(err, syscall.EWOULDBLOCK) {
+            return nil, ErrLocked
+        }
+        return nil, err
+    }
+
+    token, err := nextToken(file)
+    if err != nil {
+        file.Close()
+        return nil, err
+    }
+
+    f.mu.Lock()
+    defer f.mu.Unlock()
+
+    h := &fileLease{token: token, file: file}
+    h.timer = time.AfterFunc(ttl, func() {
+        f.expire(name, token)
+    })
+    f.held[name] = h
+
+    return &Lease{Name: name, Token: token, Expires: time.Now().Add(ttl)}, nil
+}
+
+// internal function to read the last token of a locked file, and store the
+// next one which it returns
+func nextToken(file *os.File) (uint64, error) {
+    data := make([]byte, 32)
+    n, err := file.ReadAt(data, 0)
+    if err != nil && n == 0 && !errors.Is(err, io.EOF) {
+        return 0, err
+    }
+
+    var last uint64
+    if s := strings.TrimSpace(string(data[:n])); s != "" {
+        if last, err = strconv.ParseUint(s, 10, 64); err != nil {
+            return 0, err
+        }
+    }
+
+    // The next token is never shorter than the last one, it is written
+    // over it before truncating so the file never holds a smaller token,
+    // even after a crash.
+    next := strconv.FormatUint(last+1, 10) + "\n"
+    if _, err := file.WriteAt([]byte(next), 0); err != nil {
+        return 0, err
+    }
+    if err := file.Truncate(int64(len(next))); err != nil {
+        return 0, err
+    }
+    if err := file.Sync(); err != nil {
+        return 0, err
+    }
+    return last + 1, nil
+}
+
+// internal function to find the lock file held by the lease and stop its
+// timer, nil if the lease expired or was released. The caller must hold
+// f.mu.
+func (f *FileLocker) stop(lease *Lease) *fileLease {
+    h := f.held[lease.Name]
+    if h == nil || h.token != lease.Token {
+        return nil
+    }
+
+    // The timer already fired, the lease is being released.
+    if !h.timer.Stop() {
+        return nil
+    }
+    return h
+}
+
+// Refresh extends the lease to ttl from now, see Locker
+func (f *FileLocker) Refresh(lease *Lease, ttl time.Duration) error {
+    if ttl <= 0 {
+        return ErrInvalidTTL
+    }
+
+    f.mu.Lock()
+    defer f.mu.Unlock()
+
+    h := f.stop(lease)
+    if h == nil {
+        return ErrNotHeld
+    }
+
+    h.timer.Reset(ttl)
+    lease.Expires = time.Now().Add(ttl)
+    return nil
+}
+
+// Release releases the lock held by the lease, see Locker
+func (f *FileLocker) Release(lease *Lease) error {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+
+    h := f.stop(lease)
+    if h == nil {
+        return ErrNotHeld
+    }
+
+    delete(f.held, lease.Name)
+    return h.file.Close()
+}
+
+// internal function to release the lock of an expired lease
+func (f *FileLocker) expire(name string, token uint64) {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+
+    if h := f.held[name]; h != nil && h.token == token {
+        delete(f.held, name)
+        h.file.Close()
+    }
+}
@@ -0,0 +1,166 @@
+//go:build linux
+
+package synchronous
+
+import (
+    "bufio"
+    "context"
+    "io"
+    "os"
+    "os/exec"
+    "path/filepath"
+    "testing"
+    "time"
+)
+
+func TestFileLocker(t *testing.T) {
+    testLocker(t, func(t *testing.T) Locker {
+        l, err := NewFileLocker(t.TempDir())
+        if err != nil {
+            t.Fatal(err)
+        }
+        return l
+    })
+}
+
+func TestFileLockerTokens(t *testing.T) {
+    dir := t.TempDir()
+
+    // The tokens are stored in the lock files, they keep increasing with
+    // a new locker.
+    var last uint64
+    for i := 0; i < 3; i++ {
+        l, _ := NewFileLocker(dir)
+        lease, err := l.TryAcquire("a", time.Minute)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if lease.Token != last+1 {
+            t.Errorf("the token should be %d, got %d", last+1, lease.Token)
+        }
+        last = lease.Token
+        l.Release(lease)
+    }
+
+    // A longer token replaces the last one entirely.
+    os.WriteFile(filepath.Join(dir, "b.lock"), []byte("9\n"), 0644)
+    l, _ := NewFileLocker(dir)
+    lease, err := l.TryAcquire("b", time.Minute)
+    if err != nil || lease.Token != 10 {
+        t.Fatalf("the token should be 10, got %v, %v", lease, err)
+    }
+    if data, _ := os.ReadFile(filepath.Join(dir, "b.lock")); string(data) != "10\n" {
+        t.Errorf("the lock file should hold %q, got %q", "10\n", data)
+    }
+    l.Release(lease)
+}
+
+// TestFileLockerHelperProcess holds a lock in a child process of
+// TestFileLockerProcesses until its stdin is closed
+func TestFileLockerHelperProcess(t *testing.T) {
+    dir := os.Getenv("SYNCHRONOUS_LOCK_DIR")
+    if dir == "" {
+        t.Skip("helper process of TestFileLockerProcesses")
+    }
+
+    ttl, err := time.ParseDuration(os.Getenv("SYNCHRONOUS_LOCK_TTL"))
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    l, _ := NewFileLocker(dir)
+    if _, err := l.TryAcquire("a", ttl); err != nil {
+        t.Fatal(err)
+    }
+    os.Stdout.WriteString("locked\n")
+
+    io.Copy(io.Discard, os.Stdin)
+    os.Exit(0)
+}
+
+// startHolder starts a child process holding the lock `a` in dir for ttl,
+// closing the returned writer makes it exit
+func startHolder(t *testing.T, dir string, ttl time.Duration) (*exec.Cmd, io.WriteCloser) {
+    cmd := exec.Command(os.Args[0], "-test.run=^TestFileLockerHelperProcess$")
+    cmd.Env = append(os.Environ(), "SYNCHRONOUS_LOCK_DIR="+dir, "SYNCHRONOUS_LOCK_TTL="+ttl.String())
+
+    stdin, _ := cmd.StdinPipe()
+    stdout, _ := cmd.StdoutPipe()
+    if err := cmd.Start(); err != nil {
+        t.Fatal(err)
+    }
+
+    line, err := bufio.NewReader(stdout).ReadString('\n')
+    if err != nil || line != "locked\n" {
+        t.Fatalf("the helper process should lock, got %q, %v", line, err)
+    }
+    return cmd, stdin
+}
+
+func TestFileLockerProcesses(t *testing.T) {
+    dir := t.TempDir()
+    l, _ := NewFileLocker(dir)
+
+    // The lock is released when the holder exits.
+    cmd, stdin := startHolder(t, dir, time.Minute)
+    if _, err := l.TryAcquire("a", time.Minute); err != ErrLocked {
+        t.Errorf("the lock held by another process should return ErrLocked, got %v", err)
+    }
+
+    stdin.Close()
+    ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+    defer cancel()
+    lease, err := l.Acquire(ctx, "a", time.Minute)
+    if err != nil {
+        t.Fatalf("Acquire should get the lock once the other process exits, got %v", err)
+    }
+    if lease.Token != 2 {
+        t.Errorf("the token should be 2, got %d", lease.Token)
+    }
+    l.Release(lease)
+    cmd.Wait()
+
+    // The kernel releases the lock of a crashed holder.
+    cmd, stdin = startHolder(t, dir, time.Minute)
+    defer stdin.Close()
+    cmd.Process.Kill()
+    cmd.Wait()
+
+    if lease, err = l.TryAcquire("a", time.Minute); err != nil {
+        t.Fatalf("the lock of a killed process should be released, got %v", err)
+    }
+    if lease.Token != 4 {
+        t.Errorf("the token should be 4, got %d", lease.Token)
+    }
+}
+
+func TestFileLockerProcessExpiry(t *testing.T) {
+    dir := t.TempDir()
+    l, _ := NewFileLocker(dir)
+
+    // The holder releases the lock when its lease expires, while it keeps
+    // running.
+    cmd, stdin := startHolder(t, dir, 100*time.Millisecond)
+    defer func() {
+        stdin.Close()
+        cmd.Wait()
+    }()
+
+    if _, err := l.TryAcquire("a", time.Minute); err != ErrLocked {
+        t.Errorf("the lock held by another process should return ErrLocked, got %v", err)
+    }
+
+    ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+    defer cancel()
+    lease, err := l.Acquire(ctx, "a", time.Minute)
+    if err != nil {
+        t.Fatalf("Acquire should get the lock once the lease of the other process expires, got %v", err)
+    }
+    if lease.Token != 2 {
+        t.Errorf("the token should be 2, got %d", lease.Token)
+    }
+    if cmd.ProcessState != nil {
+        t.Errorf("the holder should still be running")
+    }
+    l.Release(lease)
+}
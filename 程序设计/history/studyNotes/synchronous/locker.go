@@ -0,0 +1,74 @@
+package synchronous
+
+import (
+    "context"
+    "errors"
+    "strings"
+    "time"
+)
+
+var (
+    // ErrLocked is returned by TryAcquire when the lock is held
+    ErrLocked = errors.New("synchronous: lock is held")
+
+    // ErrNotHeld is returned by Refresh and Release when the lease expired
+    // or was released
+    ErrNotHeld = errors.New("synchronous: lease is not held")
+
+    // ErrInvalidName is returned for an empty name or a name containing a
+    // slash
+    ErrInvalidName = errors.New("synchronous: invalid lock name")
+
+    // ErrInvalidTTL is returned for a ttl which is not positive
+    ErrInvalidTTL = errors.New("synchronous: ttl must be positive")
+)
+
+// Lease the right to hold a named lock until Expires, granted by a Locker
+type Lease struct {
+    // Name the name of the lock
+    Name string
+
+    // Token the fencing token, it increases with every acquisition of the
+    // lock. A resource protected by the lock should reject the requests
+    // carrying a token lower than the highest one it has seen, so that a
+    // holder whose lease expired unnoticed(e.g. paused by GC) can't
+    // overwrite the changes of the next holder.
+    Token uint64
+
+    // Expires the time the lease expires unless it is refreshed, the lock
+    // is then released
+    Expires time.Time
+}
+
+// Locker the named locks shared by the users of the same backend: the
+// goroutines of a process for MemoryLocker, the processes of a host for
+// FileLocker.
+type Locker interface {
+    // Acquire acquires the lock `name` for ttl, blocking until it is
+    // released or expires, or ctx is done
+    Acquire(ctx context.Context, name string, ttl time.Duration) (*Lease, error)
+
+    // TryAcquire acquires the lock `name` for ttl without blocking, it
+    // returns ErrLocked if the lock is held
+    TryAcquire(name string, ttl time.Duration) (*Lease, error)
+
+    // Refresh extends the lease to ttl from now and updates its Expires,
+    // it returns ErrNotHeld if the lease expired
+    Refresh(lease *Lease, ttl time.Duration) error
+
+    // Release releases the lock, it returns ErrNotHeld if the lease
+    // expired or was already released
+    Release(lease *Lease) error
+}
+
+// internal function to check the arguments of an acquisition
+func checkLock(name string, ttl time.Duration) error {
+    if name == "" || strings.ContainsAny(name, "/\x00") || name == "." || name == ".." {
+        return ErrInvalidName
+    }
+
+    if ttl <= 0 {
+        return ErrInvalidTTL
+    }
+    return nil
+}
@@ -0,0 +1,166 @@
+package synchronous
+
+import (
+    "context"
+    "sync"
+    "testing"
+    "time"
+)
+
+// testLocker runs the tests every Locker must pass
+func testLocker(t *testing.T, newLocker func(t *testing.T) Locker) {
+    t.Run("Acquire", func(t *testing.T) {
+        l := newLocker(t)
+
+        lease, err := l.TryAcquire("a", time.Minute)
+        if err != nil {
+            t.Fatalf("TryAcquire should succeed, got %v", err)
+        }
+        if lease.Name != "a" || lease.Token == 0 || time.Until(lease.Expires) <= 0 {
+            t.Errorf("the lease should be valid, got %+v", lease)
+        }
+
+        if _, err := l.TryAcquire("a", time.Minute); err != ErrLocked {
+            t.Errorf("TryAcquire on a held lock should return ErrLocked, got %v", err)
+        }
+
+        // Locks with other names are independent.
+        other, err := l.TryAcquire("b", time.Minute)
+        if err != nil {
+            t.Fatalf("TryAcquire on another lock should succeed, got %v", err)
+        }
+        l.Release(other)
+
+        if err := l.Release(lease); err != nil {
+            t.Fatalf("Release should succeed, got %v", err)
+        }
+        if err := l.Release(lease); err != ErrNotHeld {
+            t.Errorf("a second Release should return ErrNotHeld, got %v", err)
+        }
+
+        next, err := l.Acquire(context.Background(), "a", time.Minute)
+        if err != nil {
+            t.Fatalf("Acquire after Release should succeed, got %v", err)
+        }
+        if next.Token <= lease.Token {
+            t.Errorf("the token should increase, got %d after %d", next.Token, lease.Token)
+        }
+        l.Release(next)
+    })
+
+    t.Run("Invalid", func(t *testing.T) {
+        l := newLocker(t)
+
+        for _, name := range []string{"", "a/b", ".."} {
+            if _, err := l.TryAcquire(name, time.Minute); err != ErrInvalidName {
+                t.Errorf("TryAcquire(%q) should return ErrInvalidName, got %v", name, err)
+            }
+        }
+
+        if _, err := l.Acquire(context.Background(), "a", 0); err != ErrInvalidTTL {
+            t.Errorf("Acquire with a zero ttl should return ErrInvalidTTL, got %v", err)
+        }
+    })
+
+    t.Run("Wait", func(t *testing.T) {
+        l := newLocker(t)
+        lease, _ := l.TryAcquire("a", time.Minute)
+
+        ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+        defer cancel()
+        if _, err := l.Acquire(ctx, "a", time.Minute); err != context.DeadlineExceeded {
+            t.Errorf("Acquire on a held lock should time out, got %v", err)
+        }
+
+        time.AfterFunc(20*time.Millisecond, func() {
+            l.Release(lease)
+        })
+        next, err := l.Acquire(context.Background(), "a", time.Minute)
+        if err != nil {
+            t.Fatalf("Acquire should get the released lock, got %v", err)
+        }
+        l.Release(next)
+    })
+
+    t.Run("Expiry", func(t *testing.T) {
+        l := newLocker(t)
+        lease, _ := l.TryAcquire("a", 50*time.Millisecond)
+
+        time.Sleep(20 * time.Millisecond)
+        if err := l.Refresh(lease, 100*time.Millisecond); err != nil {
+            t.Fatalf("Refresh should succeed, got %v", err)
+        }
+        if time.Until(lease.Expires) < 50*time.Millisecond {
+            t.Errorf("Refresh should update Expires, got %v", lease.Expires)
+        }
+
+        // The refreshed lease outlives the initial ttl.
+        time.Sleep(50 * time.Millisecond)
+        if _, err := l.TryAcquire("a", time.Minute); err != ErrLocked {
+            t.Errorf("the refreshed lease should still hold the lock, got %v", err)
+        }
+
+        // Another goroutine gets the lock once the lease expires.
+        next, err := l.Acquire(context.Background(), "a", time.Minute)
+        if err != nil {
+            t.Fatalf("Acquire should get the expired lock, got %v", err)
+        }
+        if next.Token <= lease.Token {
+            t.Errorf("the token should increase, got %d after %d", next.Token, lease.Token)
+        }
+
+        if err := l.Refresh(lease, time.Minute); err != ErrNotHeld {
+            t.Errorf("Refresh of an expired lease should return ErrNotHeld, got %v", err)
+        }
+        if err := l.Release(lease); err != ErrNotHeld {
+            t.Errorf("Release of an expired lease should return ErrNotHeld, got %v", err)
+        }
+        if err := l.Release(next); err != nil {
+            t.Errorf("the expired lease should not release the next one, got %v", err)
+        }
+    })
+
+    t.Run("Concurrent", func(t *testing.T) {
+        l := newLocker(t)
+
+        var (
+            wg     sync.WaitGroup
+            c      int
+            tokens []uint64
+        )
+        for i := 0; i < 10; i++ {
+            wg.Add(1)
+            go func() {
+                defer wg.Done()
+
+                for j := 0; j < 10; j++ {
+                    lease, err := l.Acquire(context.Background(), "a", time.Minute)
+                    if err != nil {
+                        t.Error(err)
+                        return
+                    }
+
+                    c++
+                    tokens = append(tokens, lease.Token)
+                    l.Release(lease)
+                }
+            }()
+        }
+        wg.Wait()
+
+        if c != 100 {
+            t.Errorf("c should be 100, got %d", c)
+        }
+        for i := 1; i < len(tokens); i++ {
+            if tokens[i] <= tokens[i-1] {
+                t.Fatalf("the tokens should increase, got %v", tokens)
+            }
+        }
+    })
+}
+
+func TestMemoryLocker(t *testing.T) {
+    testLocker(t, func(*testing.T) Locker {
+        return NewMemoryLocker()
+    })
+}
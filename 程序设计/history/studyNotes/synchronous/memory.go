@@ -0,0 +1,144 @@
+package synchronous
+
+import (
+    "context"
+    "sync"
+    "time"
+)
+
+// MemoryLocker the in-process Locker, the locks are shared by the
+// goroutines using the same MemoryLocker. An expired lease is released
+// lazily, when another goroutine tries to acquire the lock.
+type MemoryLocker struct {
+    mu    sync.Mutex
+    locks map[string]*memoryLock
+}
+
+// memoryLock the state of a lock, it is kept once released so that the
+// tokens keep increasing
+type memoryLock struct {
+    // last the last token granted
+    last uint64
+
+    // holder the token of the lease holding the lock, 0 if released
+    holder  uint64
+    expires time.Time
+
+    // changed is closed and replaced when the lock is released
+    changed chan struct{}
+}
+
+// NewMemoryLocker returns an in-process Locker
+func NewMemoryLocker() *MemoryLocker {
+    return &MemoryLocker{locks: make(map[string]*memoryLock)}
+}
+
+// Acquire acquires the lock `name` for ttl, see Locker
+func (m *MemoryLocker) Acquire(ctx context.Context, name string, ttl time.Duration) (*Lease, error) {
+    if err := checkLock(name, ttl); err != nil {
+        return nil, err
+    }
+
+    for {
+        if err := ctx.Err(); err != nil {
+            return nil, err
+        }
+
+        m.mu.Lock()
+        lease, l := m.tryAcquire(name, ttl)
+        if lease != nil {
+            m.mu.Unlock()
+            return lease, nil
+        }
+        changed, wait := l.changed, time.Until(l.expires)
+        m.mu.Unlock()
+
+        // Wait for the release or the expiry of the lease.
+        timer := time.NewTimer(wait)
+        select {
+        case <-changed:
+        case <-timer.C:
+        case <-ctx.Done():
+        }
+        timer.Stop()
+    }
+}
+
+// TryAcquire acquires the lock `name` for ttl without blocking, see Locker
+func (m *MemoryLocker) TryAcquire(name string, ttl time.Duration) (*Lease, error) {
+    if err := checkLock(name, ttl); err != nil {
+        return nil, err
+    }
+
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    if lease, _ := m.tryAcquire(name, ttl); lease != nil {
+        return lease, nil
+    }
+    return nil, ErrLocked
+}
+
+// internal function to acquire the lock if it is released or expired, it
+// returns the state of the lock otherwise. The caller must hold m.mu.
+func (m *MemoryLocker) tryAcquire(name string, ttl time.Duration) (*Lease, *memoryLock) {
+    l := m.locks[name]
+    if l == nil {
+        l = &memoryLock{changed: make(chan struct{})}
+        m.locks[name] = l
+    }
+
+    now := time.Now()
+    if l.holder != 0 && now.Before(l.expires) {
+        return nil, l
+    }
+
+    l.last++
+    l.holder, l.expires = l.last, now.Add(ttl)
+    return &Lease{Name: name, Token: l.holder, Expires: l.expires}, l
+}
+
+// internal function to find the lock held by the lease, nil if the lease
+// expired or was released. The caller must hold m.mu.
+func (m *MemoryLocker) held(lease *Lease) *memoryLock {
+    l := m.locks[lease.Name]
+    if l == nil || l.holder != lease.Token || !time.Now().Before(l.expires) {
+        return nil
+    }
+    return l
+}
+
+// Refresh extends the lease to ttl from now, see Locker
+func (m *MemoryLocker) Refresh(lease *Lease, ttl time.Duration) error {
+    if ttl <= 0 {
+        return ErrInvalidTTL
+    }
+
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    l := m.held(lease)
+    if l == nil {
+        return ErrNotHeld
+    }
+
+    l.expires = time.Now().Add(ttl)
+    lease.Expires = l.expires
+    return nil
+}
+
+// Release releases the lock held by the lease, see Locker
+func (m *MemoryLocker) Release(lease *Lease) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    l := m.held(lease)
+    if l == nil {
+        return ErrNotHeld
+    }
+
+    l.holder = 0
+    close(l.changed)
+    l.changed = make(chan struct{})
+    return nil
+}
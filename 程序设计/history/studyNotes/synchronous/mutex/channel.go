@@ -1,25 +1,136 @@
 package syncTest 
 
 import (
+    "context"
     "sync"
+    "time"
 )
 
+// Lock the mutual exclusion lock built on a channel, it must be created
+// with NewLock. The channel holds a token while the lock is free, so
+// acquiring it can be combined with other cases in a select.
 type Lock struct{
     ch chan struct{}
+
+    // probe records the contention once a Profiler is set
+    probe probe
 }
 
+// NewLock returns an unlocked Lock
 func NewLock() *Lock {
-    return &Lock{
+    t := &Lock{
         ch: make(chan struct{}, 1),
     }
+    t.ch <- struct{}{}
+    return t
+}
+
+// SetProfiler records the contention of t in p, it must be called before
+// t is used
+func (t *Lock) SetProfiler(p *Profiler) {
+    t.probe.prof = p
 }
 
+// Lock locks t, blocking until the lock is available
 func (t *Lock) Lock() {
-    <-t.ch
+    if t.probe.prof == nil {
+        <-t.ch
+        return
+    }
+
+    start := time.Now()
+    contended := !t.tryLock()
+    if contended {
+        <-t.ch
+    }
+    t.probe.locked(1, start, contended)
+}
+
+// TryLock tries to lock t without blocking, it returns false if the lock
+// is held
+func (t *Lock) TryLock() bool {
+    if !t.tryLock() {
+        return false
+    }
+
+    if t.probe.prof != nil {
+        t.probe.locked(1, time.Now(), false)
+    }
+    return true
+}
+
+// internal function to lock t without blocking nor profiling
+func (t *Lock) tryLock() bool {
+    select {
+    case <-t.ch:
+        return true
+    default:
+        return false
+    }
+}
+
+// LockContext locks t, blocking until the lock is available or ctx is
+// done, in which case it returns ctx.Err()
+func (t *Lock) LockContext(ctx context.Context) error {
+    // Don't race the lock against a context already done.
+    if err := ctx.Err(); err != nil {
+        return err
+    }
+
+    start := time.Now()
+    contended := !t.tryLock()
+    if contended {
+        select {
+        case <-t.ch:
+        case <-ctx.Done():
+            if t.probe.prof != nil {
+                t.probe.failed(1, start)
+            }
+            return ctx.Err()
+        }
+    }
+
+    if t.probe.prof != nil {
+        t.probe.locked(1, start, contended)
+    }
+    return nil
+}
+
+// LockTimeout locks t, blocking at most d, it returns false if the lock
+// could not be acquired in time
+func (t *Lock) LockTimeout(d time.Duration) bool {
+    start := time.Now()
+    contended := !t.tryLock()
+    if contended {
+        timer := time.NewTimer(d)
+        defer timer.Stop()
+
+        select {
+        case <-t.ch:
+        case <-timer.C:
+            if t.probe.prof != nil {
+                t.probe.failed(1, start)
+            }
+            return false
+        }
+    }
+
+    if t.probe.prof != nil {
+        t.probe.locked(1, start, contended)
+    }
+    return true
 }
 
+// Unlock unlocks t, it panics if t is not locked. Like sync.Mutex, the
+// lock is not tied to a goroutine.
 func (t *Lock) Unlock() {
-    t.ch <- struct{}{}
+    t.probe.unlocked()
+
+    select {
+    case t.ch <- struct{}{}:
+    default:
+        panic("syncTest: unlock of unlocked Lock")
+    }
 }
 
 func addUseChan(c *int) {
@@ -33,7 +144,6 @@ func UseChan() {
     )
 
     var t = NewLock()
-    t.ch <- struct{}{}
 
     for i := 0; i < 5; i++ {
         wg.Add(1)
@@ -0,0 +1,205 @@
+package syncTest
+
+import (
+    "flag"
+    "fmt"
+    "os"
+    "runtime"
+    "slices"
+    "strings"
+    "sync"
+    "sync/atomic"
+    "testing"
+    "text/tabwriter"
+)
+
+var table = flag.Bool("table", false, "print the table comparing the primitives under contention")
+
+// The parameters swept by BenchmarkContention and TestContentionTable.
+var (
+    sweepGoroutines = []int{1, 8, 64}
+
+    // the iterations of work done in the critical section
+    sweepWork = []int{0, 100, 1000}
+)
+
+// sweepProcs returns the GOMAXPROCS values to sweep
+func sweepProcs() []int {
+    procs := []int{1, 4, runtime.NumCPU()}
+    slices.Sort(procs)
+    return slices.Compact(procs)
+}
+
+// work simulates a critical section of n iterations
+func work(n int) int64 {
+    var x int64
+    for i := 0; i < n; i++ {
+        x += int64(i) * int64(i)
+    }
+    return x
+}
+
+// spinLock the test-and-set lock, it yields the processor after a few
+// failed attempts so that it doesn't starve the holder when GOMAXPROCS is
+// low
+type spinLock struct {
+    state atomic.Int32
+}
+
+func (l *spinLock) Lock() {
+    for i := 0; !l.state.CompareAndSwap(0, 1); i++ {
+        if i%16 == 15 {
+            runtime.Gosched()
+        }
+    }
+}
+
+func (l *spinLock) Unlock() {
+    l.state.Store(0)
+}
+
+// primitive a synchronization primitive protecting a counter, newOp
+// returns the operation updating the counter after n iterations of work,
+// it returns the counter so that the work can't be optimised away
+type primitive struct {
+    name  string
+    newOp func() func(n int) int64
+}
+
+// sink receives the results of the operations
+var sink atomic.Int64
+
+// locked returns the operation of a lock
+func locked(l sync.Locker) func(n int) int64 {
+    var c int64
+    return func(n int) int64 {
+        l.Lock()
+        c += work(n)
+        r := c
+        l.Unlock()
+        return r
+    }
+}
+
+var primitives = []primitive{
+    {"chan", func() func(int) int64 { return locked(NewLock()) }},
+    {"fair", func() func(int) int64 { return locked(&FairMutex{}) }},
+    {"mutex", func() func(int) int64 { return locked(&sync.Mutex{}) }},
+    {"rwmutex", func() func(int) int64 { return locked(&sync.RWMutex{}) }},
+    {"rwmutex-read", func() func(int) int64 {
+        // The readers share the lock, the counter is only read.
+        var (
+            m sync.RWMutex
+            c int64
+        )
+        return func(n int) int64 {
+            m.RLock()
+            r := c + work(n)
+            m.RUnlock()
+            return r
+        }
+    }},
+    {"spin", func() func(int) int64 { return locked(&spinLock{}) }},
+    {"atomic", func() func(int) int64 {
+        // The work doesn't need a lock, only the update of the counter.
+        var c atomic.Int64
+        return func(n int) int64 {
+            return c.Add(work(n))
+        }
+    }},
+}
+
+// runContention runs b.N operations of p split between goroutines
+func runContention(b *testing.B, p primitive, goroutines, n int) {
+    op := p.newOp()
+
+    var wg sync.WaitGroup
+    start := make(chan struct{})
+    for g := 0; g < goroutines; g++ {
+        ops := b.N / goroutines
+        if g < b.N%goroutines {
+            ops++
+        }
+
+        wg.Add(1)
+        go func(ops int) {
+            defer wg.Done()
+
+            <-start
+            var sum int64
+            for i := 0; i < ops; i++ {
+                sum += op(n)
+            }
+            sink.Add(sum)
+        }(ops)
+    }
+
+    b.ResetTimer()
+    close(start)
+    wg.Wait()
+}
+
+// withProcs runs f with GOMAXPROCS set to procs
+func withProcs(procs int, f func()) {
+    defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(procs))
+    f()
+}
+
+// BenchmarkContention sweeps GOMAXPROCS, the number of goroutines and the
+// length of the critical section over the primitives, compare them with
+//
+//    go test -run xxx -bench Contention -count 10 > bench.txt
+//    benchstat -col /lock bench.txt
+func BenchmarkContention(b *testing.B) {
+    for _, procs := range sweepProcs() {
+        for _, g := range sweepGoroutines {
+            for _, n := range sweepWork {
+                for _, p := range primitives {
+                    name := fmt.Sprintf("procs=%d/goroutines=%d/work=%d/lock=%s", procs, g, n, p.name)
+                    b.Run(name, func(b *testing.B) {
+                        withProcs(procs, func() {
+                            runContention(b, p, g, n)
+                        })
+                    })
+                }
+            }
+        }
+    }
+}
+
+// TestContentionTable prints the ns/op of every primitive for every
+// parameters of BenchmarkContention, one row per parameters:
+//
+//    go test -run ContentionTable -table -benchtime 100ms
+func TestContentionTable(t *testing.T) {
+    if !*table {
+        t.Skip("run with -table to print the table")
+    }
+
+    w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+    defer w.Flush()
+
+    header := []string{"procs", "goroutines", "work"}
+    for _, p := range primitives {
+        header = append(header, p.name)
+    }
+    fmt.Fprintln(w, strings.Join(header, "\t")+"\t")
+
+    for _, procs := range sweepProcs() {
+        for _, g := range sweepGoroutines {
+            for _, n := range sweepWork {
+                row := []string{fmt.Sprint(procs), fmt.Sprint(g), fmt.Sprint(n)}
+                for _, p := range primitives {
+                    var r testing.BenchmarkResult
+                    withProcs(procs, func() {
+                        r = testing.Benchmark(func(b *testing.B) {
+                            runContention(b, p, g, n)
+                        })
+                    })
+                    row = append(row, fmt.Sprint(r.NsPerOp()))
+                }
+                fmt.Fprintln(w, strings.Join(row, "\t")+"\t")
+            }
+        }
+    }
+}
@@ -0,0 +1,138 @@
+package syncTest 
+
+import (
+    "context"
+    "sync"
+    "time"
+)
+
+// FairMutex the ticket lock, goroutines get the lock in the order they
+// asked for it(FIFO), whereas sync.Mutex lets a new goroutine barge in
+// ahead of the waiting ones. The zero value is an unlocked mutex.
+type FairMutex struct {
+    mu sync.Mutex
+
+    // next the ticket handed out by the next Lock
+    next uint64
+
+    // serving the ticket holding the lock, the lock is free when it equals
+    // next
+    serving uint64
+
+    // waiters the channels closed to hand the lock to the waiting tickets,
+    // a canceled ticket is removed and skipped
+    waiters map[uint64]chan struct{}
+
+    // probe records the contention once a Profiler is set
+    probe probe
+}
+
+// SetProfiler records the contention of t in p, it must be called before
+// t is used
+func (t *FairMutex) SetProfiler(p *Profiler) {
+    t.probe.prof = p
+}
+
+// Lock locks t, blocking until the goroutines which asked before got and
+// released the lock
+func (t *FairMutex) Lock() {
+    t.lockContext(context.Background(), 1)
+}
+
+// TryLock tries to lock t without blocking, it returns false if the lock is
+// held
+func (t *FairMutex) TryLock() bool {
+    t.mu.Lock()
+    if t.serving != t.next {
+        t.mu.Unlock()
+        return false
+    }
+    t.next++
+    t.mu.Unlock()
+
+    if t.probe.prof != nil {
+        t.probe.locked(1, time.Now(), false)
+    }
+    return true
+}
+
+// LockContext locks t like Lock, it gives up its turn and returns ctx.Err()
+// if ctx is done first
+func (t *FairMutex) LockContext(ctx context.Context) error {
+    return t.lockContext(ctx, 1)
+}
+
+// internal function to lock t, skip is the number of frames to skip above
+// the caller in the profile
+func (t *FairMutex) lockContext(ctx context.Context, skip int) error {
+    if err := ctx.Err(); err != nil {
+        return err
+    }
+
+    start := time.Now()
+    t.mu.Lock()
+    ticket := t.next
+    t.next++
+    if ticket == t.serving {
+        t.mu.Unlock()
+        if t.probe.prof != nil {
+            t.probe.locked(skip+1, start, false)
+        }
+        return nil
+    }
+
+    ready := make(chan struct{})
+    if t.waiters == nil {
+        t.waiters = make(map[uint64]chan struct{})
+    }
+    t.waiters[ticket] = ready
+    t.mu.Unlock()
+
+    select {
+    case <-ready:
+        if t.probe.prof != nil {
+            t.probe.locked(skip+1, start, true)
+        }
+        return nil
+    case <-ctx.Done():
+        t.mu.Lock()
+        if _, ok := t.waiters[ticket]; ok {
+            delete(t.waiters, ticket)
+        } else {
+            // The lock was handed over meanwhile, pass it on.
+            t.handOver()
+        }
+        t.mu.Unlock()
+
+        if t.probe.prof != nil {
+            t.probe.failed(skip+1, start)
+        }
+        return ctx.Err()
+    }
+}
+
+// Unlock unlocks t and hands the lock to the next waiting ticket, it panics
+// if t is not locked
+func (t *FairMutex) Unlock() {
+    t.probe.unlocked()
+
+    t.mu.Lock()
+    defer t.mu.Unlock()
+
+    if t.serving == t.next {
+        panic("syncTest: unlock of unlocked FairMutex")
+    }
+    t.handOver()
+}
+
+// internal function to serve the next ticket not canceled, the caller must
+// hold t.mu
+func (t *FairMutex) handOver() {
+    for t.serving++; t.serving != t.next; t.serving++ {
+        if ready, ok := t.waiters[t.serving]; ok {
+            delete(t.waiters, t.serving)
+            close(ready)
+            return
+        }
+    }
+}
@@ -0,0 +1,400 @@
+package syncTest 
+
+import (
+    "compress/gzip"
+    "fmt"
+    "io"
+    "runtime"
+    "sort"
+    "sync"
+    "sync/atomic"
+    "time"
+)
+
+// maxStack the maximum depth of the call stacks recorded by Profiler
+const maxStack = 32
+
+// Profiler records the contention of the locks it is attached to, per call
+// site: how many times they were acquired, how many of them had to wait,
+// how long they waited and how long the lock was held afterwards. Unlike
+// runtime.SetMutexProfileFraction it only watches the chosen locks, see
+// Lock.SetProfiler, RWMutex.SetProfiler, FairMutex.SetProfiler and Wrap.
+type Profiler struct {
+    start time.Time
+
+    // records maps a call stack to its *siteRecord, the profiled locks
+    // only load it and update the record with atomics, so they don't
+    // contend on the profiler
+    records sync.Map
+}
+
+// siteRecord the counters of a Record, updated concurrently
+type siteRecord struct {
+    stack        []uintptr
+    acquisitions atomic.Int64
+    contentions  atomic.Int64
+    wait         atomic.Int64
+    hold         atomic.Int64
+}
+
+// Record the contention of the locks acquired from the same call stack
+type Record struct {
+    // Stack the return program counters from the caller of the lock
+    // method, see runtime.CallersFrames
+    Stack []uintptr
+
+    // Acquisitions the number of times the lock was acquired
+    Acquisitions int64
+
+    // Contentions the number of times the caller had to wait, including
+    // the waits canceled by a context or a timeout
+    Contentions int64
+
+    // Wait the total time spent waiting for the lock
+    Wait time.Duration
+
+    // Hold the total time the lock was held, the read locks of RWMutex
+    // are not counted
+    Hold time.Duration
+}
+
+// Site returns the function, file and line of the call site
+func (r *Record) Site() string {
+    frames := runtime.CallersFrames(r.Stack)
+    f, _ := frames.Next()
+    return fmt.Sprintf("%s %s:%d", f.Function, f.File, f.Line)
+}
+
+// NewProfiler returns an empty Profiler
+func NewProfiler() *Profiler {
+    return &Profiler{start: time.Now()}
+}
+
+// Records returns a copy of the records, sorted by decreasing wait time
+func (p *Profiler) Records() []Record {
+    var records []Record
+    p.records.Range(func(_, v any) bool {
+        r := v.(*siteRecord)
+        records = append(records, Record{
+            Stack:        r.stack,
+            Acquisitions: r.acquisitions.Load(),
+            Contentions:  r.contentions.Load(),
+            Wait:         time.Duration(r.wait.Load()),
+            Hold:         time.Duration(r.hold.Load()),
+        })
+        return true
+    })
+
+    sort.Slice(records, func(i, j int) bool {
+        if records[i].Wait != records[j].Wait {
+            return records[i].Wait > records[j].Wait
+        }
+        return records[i].Hold > records[j].Hold
+    })
+    return records
+}
+
+// internal function to find the record of the call stack, skip is the
+// number of frames to skip above the caller
+func (p *Profiler) site(skip int) *siteRecord {
+    var stack [maxStack]uintptr
+    n := runtime.Callers(skip+2, stack[:])
+
+    if r, ok := p.records.Load(stack); ok {
+        return r.(*siteRecord)
+    }
+
+    r, _ := p.records.LoadOrStore(stack, &siteRecord{stack: append([]uintptr(nil), stack[:n]...)})
+    return r.(*siteRecord)
+}
+
+// internal function to add an acquisition, or a failed attempt, to a record
+func (r *siteRecord) add(wait time.Duration, contended, acquired bool) {
+    if acquired {
+        r.acquisitions.Add(1)
+    }
+    if contended {
+        r.contentions.Add(1)
+    }
+    r.wait.Add(int64(wait))
+}
+
+// probe the profiling state of a lock held by a single owner, the fields
+// are only accessed by the owner of the lock
+type probe struct {
+    prof  *Profiler
+    held  *siteRecord
+    since time.Time
+}
+
+// internal function to record the acquisition of the lock, it must be
+// called once the lock is held. skip is the number of frames to skip above
+// the caller.
+func (pr *probe) locked(skip int, start time.Time, contended bool) {
+    now := time.Now()
+    r := pr.prof.site(skip + 1)
+    r.add(now.Sub(start), contended, true)
+    pr.held, pr.since = r, now
+}
+
+// internal function to record the acquisition of a lock shared with other
+// owners, such as a read lock, its hold time is not recorded. skip as in
+// locked.
+func (pr *probe) shared(skip int, start time.Time, contended bool) {
+    wait := time.Since(start)
+    pr.prof.site(skip+1).add(wait, contended, true)
+}
+
+// internal function to record a wait given up, skip as in locked
+func (pr *probe) failed(skip int, start time.Time) {
+    wait := time.Since(start)
+    pr.prof.site(skip+1).add(wait, true, false)
+}
+
+// internal function to record the hold time, it must be called before the
+// lock is released
+func (pr *probe) unlocked() {
+    if pr.held == nil {
+        return
+    }
+
+    pr.held.hold.Add(int64(time.Since(pr.since)))
+    pr.held = nil
+}
+
+// TryLocker a lock which can be tried without blocking, such as sync.Mutex
+// and the locks of this package
+type TryLocker interface {
+    sync.Locker
+    TryLock() bool
+}
+
+// profiledLocker the lock returned by Wrap
+type profiledLocker struct {
+    l     TryLocker
+    probe probe
+}
+
+// Wrap returns a lock recording the contention of l in p, for the locks
+// which can't be attached to a Profiler such as sync.Mutex. l must only be
+// used through the returned lock.
+func (p *Profiler) Wrap(l TryLocker) sync.Locker {
+    return &profiledLocker{l: l, probe: probe{prof: p}}
+}
+
+func (t *profiledLocker) Lock() {
+    start := time.Now()
+    contended := !t.l.TryLock()
+    if contended {
+        t.l.Lock()
+    }
+    t.probe.locked(1, start, contended)
+}
+
+func (t *profiledLocker) Unlock() {
+    t.probe.unlocked()
+    t.l.Unlock()
+}
+
+// WriteTo writes the records as a gzipped pprof profile, with the sample
+// types acquisitions, contentions, wait and hold:
+//
+//    go tool pprof -sample_index=wait lock.pprof
+func (p *Profiler) WriteTo(w io.Writer) (int64, error) {
+    records := p.Records()
+    b := newProfileBuilder()
+
+    var buf protoBuffer
+    for _, st := range [][2]string{
+        {"acquisitions", "count"},
+        {"contentions", "count"},
+        {"wait", "nanoseconds"},
+        {"hold", "nanoseconds"},
+    } {
+        buf.message(1, func(m *protoBuffer) {
+            m.int64Field(1, b.str(st[0]))
+            m.int64Field(2, b.str(st[1]))
+        })
+    }
+
+    buf.message(11, func(m *protoBuffer) {
+        m.int64Field(1, b.str("acquisitions"))
+        m.int64Field(2, b.str("count"))
+    })
+    buf.int64Field(12, 1)
+
+    for _, r := range records {
+        locations := b.locations(r.Stack)
+        buf.message(2, func(m *protoBuffer) {
+            m.packed(1, locations)
+            m.packed(2, []uint64{
+                uint64(r.Acquisitions),
+                uint64(r.Contentions),
+                uint64(r.Wait),
+                uint64(r.Hold),
+            })
+        })
+    }
+
+    buf.data = append(buf.data, b.locs.data...)
+    buf.data = append(buf.data, b.funcs.data...)
+    for _, s := range b.strings {
+        buf.stringField(6, s)
+    }
+
+    buf.int64Field(9, p.start.UnixNano())
+    buf.int64Field(10, int64(time.Since(p.start)))
+
+    cw := &countWriter{w: w}
+    zw := gzip.NewWriter(cw)
+    if _, err := zw.Write(buf.data); err != nil {
+        return cw.n, err
+    }
+    err := zw.Close()
+    return cw.n, err
+}
+
+// countWriter counts the bytes written to w
+type countWriter struct {
+    w io.Writer
+    n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+    n, err := c.w.Write(p)
+    c.n += int64(n)
+    return n, err
+}
+
+// profileBuilder the tables of a profile, the locations and the functions
+// are encoded as they are added. The string table must be written last,
+// str may still add to it until then.
+type profileBuilder struct {
+    strings   []string
+    stringIDs map[string]int64
+    locs      protoBuffer
+    locIDs    map[runtime.Frame]uint64
+    funcs     protoBuffer
+    funcIDs   map[string]uint64
+}
+
+func newProfileBuilder() *profileBuilder {
+    return &profileBuilder{
+        strings:   []string{""},
+        stringIDs: map[string]int64{"": 0},
+        locIDs:    make(map[runtime.Frame]uint64),
+        funcIDs:   make(map[string]uint64),
+    }
+}
+
+// internal function to return the index of s in the string table
+func (b *profileBuilder) str(s string) int64 {
+    id, ok := b.stringIDs[s]
+    if !ok {
+        id = int64(len(b.strings))
+        b.strings = append(b.strings, s)
+        b.stringIDs[s] = id
+    }
+    return id
+}
+
+// internal function to return the location ids of a stack, one location
+// per frame so that the inlined calls are kept, leaf first
+func (b *profileBuilder) locations(stack []uintptr) []uint64 {
+    var ids []uint64
+
+    frames := runtime.CallersFrames(stack)
+    for {
+        f, more := frames.Next()
+
+        // Frames are compared without the pointers to runtime data.
+        f.Func = nil
+        id, ok := b.locIDs[f]
+        if !ok {
+            id = uint64(len(b.locIDs) + 1)
+            b.locIDs[f] = id
+
+            fn := b.function(f)
+            b.locs.message(4, func(m *protoBuffer) {
+                m.uint64Field(1, id)
+                m.uint64Field(3, uint64(f.PC))
+                m.message(4, func(l *protoBuffer) {
+                    l.uint64Field(1, fn)
+                    l.int64Field(2, int64(f.Line))
+                })
+            })
+        }
+        ids = append(ids, id)
+
+        if !more {
+            return ids
+        }
+    }
+}
+
+// internal function to return the function id of a frame
+func (b *profileBuilder) function(f runtime.Frame) uint64 {
+    id, ok := b.funcIDs[f.Function]
+    if !ok {
+        id = uint64(len(b.funcIDs) + 1)
+        b.funcIDs[f.Function] = id
+
+        b.funcs.message(5, func(m *protoBuffer) {
+            m.uint64Field(1, id)
+            m.int64Field(2, b.str(f.Function))
+            m.int64Field(3, b.str(f.Function))
+            m.int64Field(4, b.str(f.File))
+        })
+    }
+    return id
+}
+
+// protoBuffer the protocol buffer encoder of the few types used by the
+// pprof profile.proto
+type protoBuffer struct {
+    data []byte
+}
+
+func (b *protoBuffer) varint(x uint64) {
+    for x >= 0x80 {
+        b.data = append(b.data, byte(x)|0x80)
+        x >>= 7
+    }
+    b.data = append(b.data, byte(x))
+}
+
+func (b *protoBuffer) uint64Field(tag int, x uint64) {
+    if x == 0 {
+        return
+    }
+    b.varint(uint64(tag)<<3 | 0)
+    b.varint(x)
+}
+
+func (b *protoBuffer) int64Field(tag int, x int64) {
+    b.uint64Field(tag, uint64(x))
+}
+
+func (b *protoBuffer) bytesField(tag int, data []byte) {
+    b.varint(uint64(tag)<<3 | 2)
+    b.varint(uint64(len(data)))
+    b.data = append(b.data, data...)
+}
+
+func (b *protoBuffer) stringField(tag int, s string) {
+    b.bytesField(tag, []byte(s))
+}
+
+func (b *protoBuffer) packed(tag int, xs []uint64) {
+    var p protoBuffer
+    for _, x := range xs {
+        p.varint(x)
+    }
+    b.bytesField(tag, p.data)
+}
+
+func (b *protoBuffer) message(tag int, f func(m *protoBuffer)) {
+    var m protoBuffer
+    f(&m)
+    b.bytesField(tag, m.data)
+}
@@ -0,0 +1,213 @@
+package syncTest 
+
+import (
+    "context"
+    "time"
+)
+
+// RWMutex the reader/writer lock built on channels, it must be created
+// with NewRWMutex. It is writer-preferring: once a writer waits, new
+// readers wait behind it, so readers can't starve writers. Waiting can be
+// interrupted with RLockContext and LockContext, unlike sync.RWMutex.
+type RWMutex struct {
+    // mu guards the fields below
+    mu *Lock
+
+    // active readers
+    readers int
+
+    // writer is true while a writer holds the lock
+    writer bool
+
+    // writers blocked in LockContext
+    waitingWriters int
+
+    // changed is closed and replaced whenever the state changes, the
+    // waiters then check again whether they can lock
+    changed chan struct{}
+
+    // probe records the contention once a Profiler is set, its hold time
+    // is the one of the writers
+    probe probe
+}
+
+// NewRWMutex returns an unlocked RWMutex
+func NewRWMutex() *RWMutex {
+    return &RWMutex{
+        mu:      NewLock(),
+        changed: make(chan struct{}),
+    }
+}
+
+// SetProfiler records the contention of t in p, it must be called before
+// t is used. The time the read locks are held is not recorded.
+func (t *RWMutex) SetProfiler(p *Profiler) {
+    t.probe.prof = p
+}
+
+// RLock locks t for reading
+func (t *RWMutex) RLock() {
+    t.rLockContext(context.Background(), 1)
+}
+
+// TryRLock tries to lock t for reading without blocking, it returns false
+// if a writer holds or waits for the lock
+func (t *RWMutex) TryRLock() bool {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+
+    if t.writer || t.waitingWriters > 0 {
+        return false
+    }
+    t.readers++
+
+    if t.probe.prof != nil {
+        t.probe.shared(1, time.Now(), false)
+    }
+    return true
+}
+
+// RLockContext locks t for reading, blocking until no writer holds or waits
+// for the lock or ctx is done, in which case it returns ctx.Err()
+func (t *RWMutex) RLockContext(ctx context.Context) error {
+    return t.rLockContext(ctx, 1)
+}
+
+// internal function to lock t for reading, skip is the number of frames to
+// skip above the caller in the profile
+func (t *RWMutex) rLockContext(ctx context.Context, skip int) error {
+    if err := ctx.Err(); err != nil {
+        return err
+    }
+
+    start := time.Now()
+    for contended := false; ; contended = true {
+        t.mu.Lock()
+        if !t.writer && t.waitingWriters == 0 {
+            t.readers++
+            t.mu.Unlock()
+
+            if t.probe.prof != nil {
+                t.probe.shared(skip+1, start, contended)
+            }
+            return nil
+        }
+        changed := t.changed
+        t.mu.Unlock()
+
+        select {
+        case <-changed:
+        case <-ctx.Done():
+            if t.probe.prof != nil {
+                t.probe.failed(skip+1, start)
+            }
+            return ctx.Err()
+        }
+    }
+}
+
+// RUnlock undoes a single RLock, it panics if t is not locked for reading
+func (t *RWMutex) RUnlock() {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+
+    if t.readers == 0 {
+        panic("syncTest: RUnlock of unlocked RWMutex")
+    }
+
+    t.readers--
+    if t.readers == 0 {
+        t.broadcast()
+    }
+}
+
+// Lock locks t for writing
+func (t *RWMutex) Lock() {
+    t.lockContext(context.Background(), 1)
+}
+
+// TryLock tries to lock t for writing without blocking, it returns false if
+// the lock is held
+func (t *RWMutex) TryLock() bool {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+
+    if t.writer || t.readers > 0 {
+        return false
+    }
+    t.writer = true
+
+    if t.probe.prof != nil {
+        t.probe.locked(1, time.Now(), false)
+    }
+    return true
+}
+
+// LockContext locks t for writing, blocking until the readers and the
+// writer holding it release it or ctx is done, in which case it returns
+// ctx.Err()
+func (t *RWMutex) LockContext(ctx context.Context) error {
+    return t.lockContext(ctx, 1)
+}
+
+// internal function to lock t for writing, skip is the number of frames to
+// skip above the caller in the profile
+func (t *RWMutex) lockContext(ctx context.Context, skip int) error {
+    if err := ctx.Err(); err != nil {
+        return err
+    }
+
+    start := time.Now()
+    t.mu.Lock()
+    t.waitingWriters++
+    for contended := false; ; contended = true {
+        if !t.writer && t.readers == 0 {
+            t.waitingWriters--
+            t.writer = true
+            t.mu.Unlock()
+
+            if t.probe.prof != nil {
+                t.probe.locked(skip+1, start, contended)
+            }
+            return nil
+        }
+        changed := t.changed
+        t.mu.Unlock()
+
+        select {
+        case <-changed:
+            t.mu.Lock()
+        case <-ctx.Done():
+            // The readers blocked behind this writer may go on.
+            t.mu.Lock()
+            t.waitingWriters--
+            t.broadcast()
+            t.mu.Unlock()
+
+            if t.probe.prof != nil {
+                t.probe.failed(skip+1, start)
+            }
+            return ctx.Err()
+        }
+    }
+}
+
+// Unlock unlocks t for writing, it panics if t is not locked for writing
+func (t *RWMutex) Unlock() {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+
+    if !t.writer {
+        panic("syncTest: Unlock of unlocked RWMutex")
+    }
+
+    t.probe.unlocked()
+    t.writer = false
+    t.broadcast()
+}
+
+// internal function to wake all the waiters, the caller must hold t.mu
+func (t *RWMutex) broadcast() {
+    close(t.changed)
+    t.changed = make(chan struct{})
+}
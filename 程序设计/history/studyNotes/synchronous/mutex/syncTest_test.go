@@ -1,7 +1,14 @@
 package syncTest
 
 import (
+    "bytes"
+    "compress/gzip"
+    "context"
+    "io"
+    "strings"
+    "sync"
     "testing"
+    "time"
 )
 
 func BenchmarkUseChan(b *testing.B) {
@@ -15,3 +22,541 @@ func BenchmarkUseMutex(b *testing.B) {
         UseMutex()
     }
 }
+
+func TestLock(t *testing.T) {
+    var (
+        c  int
+        wg sync.WaitGroup
+    )
+
+    l := NewLock()
+    for i := 0; i < 100; i++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+
+            for j := 0; j < 100; j++ {
+                l.Lock()
+                c++
+                l.Unlock()
+            }
+        }()
+    }
+    wg.Wait()
+
+    if c != 10000 {
+        t.Errorf("c should be 10000, got %d", c)
+    }
+}
+
+func TestTryLock(t *testing.T) {
+    l := NewLock()
+
+    if !l.TryLock() {
+        t.Fatalf("TryLock on a new lock should succeed")
+    }
+
+    if l.TryLock() {
+        t.Errorf("TryLock on a held lock should fail")
+    }
+
+    l.Unlock()
+    if !l.TryLock() {
+        t.Errorf("TryLock after Unlock should succeed")
+    }
+}
+
+func TestLockContext(t *testing.T) {
+    l := NewLock()
+
+    if err := l.LockContext(context.Background()); err != nil {
+        t.Fatalf("LockContext on a new lock should succeed, got %v", err)
+    }
+
+    ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+    defer cancel()
+    if err := l.LockContext(ctx); err != context.DeadlineExceeded {
+        t.Errorf("LockContext on a held lock should time out, got %v", err)
+    }
+
+    // A waiter gets the lock once it is released.
+    done := make(chan error)
+    go func() {
+        done <- l.LockContext(context.Background())
+    }()
+
+    time.Sleep(10 * time.Millisecond)
+    l.Unlock()
+    if err := <-done; err != nil {
+        t.Errorf("the waiter should get the lock, got %v", err)
+    }
+
+    // A context already done never gets the lock.
+    l.Unlock()
+    ctx, cancel = context.WithCancel(context.Background())
+    cancel()
+    if err := l.LockContext(ctx); err != context.Canceled {
+        t.Errorf("LockContext with a canceled context should fail, got %v", err)
+    }
+    if !l.TryLock() {
+        t.Errorf("a failed LockContext should leave the lock free")
+    }
+}
+
+func TestLockTimeout(t *testing.T) {
+    l := NewLock()
+
+    if !l.LockTimeout(0) {
+        t.Fatalf("LockTimeout on a new lock should succeed")
+    }
+
+    start := time.Now()
+    if l.LockTimeout(20 * time.Millisecond) {
+        t.Errorf("LockTimeout on a held lock should fail")
+    }
+    if d := time.Since(start); d < 20*time.Millisecond {
+        t.Errorf("LockTimeout should wait 20ms, waited %v", d)
+    }
+
+    go func() {
+        time.Sleep(10 * time.Millisecond)
+        l.Unlock()
+    }()
+    if !l.LockTimeout(time.Second) {
+        t.Errorf("LockTimeout should get the lock once released")
+    }
+}
+
+func TestUnlockOfUnlocked(t *testing.T) {
+    defer func() {
+        if recover() == nil {
+            t.Errorf("Unlock of an unlocked Lock should panic")
+        }
+    }()
+
+    NewLock().Unlock()
+}
+
+func TestRWMutex(t *testing.T) {
+    var (
+        c  int
+        wg sync.WaitGroup
+    )
+
+    m := NewRWMutex()
+    for i := 0; i < 50; i++ {
+        wg.Add(2)
+        go func() {
+            defer wg.Done()
+
+            for j := 0; j < 100; j++ {
+                m.Lock()
+                c++
+                m.Unlock()
+            }
+        }()
+        go func() {
+            defer wg.Done()
+
+            for j := 0; j < 100; j++ {
+                m.RLock()
+                _ = c
+                m.RUnlock()
+            }
+        }()
+    }
+    wg.Wait()
+
+    if c != 5000 {
+        t.Errorf("c should be 5000, got %d", c)
+    }
+}
+
+func TestRWMutexReaders(t *testing.T) {
+    m := NewRWMutex()
+
+    m.RLock()
+    if !m.TryRLock() {
+        t.Errorf("readers should share the lock")
+    }
+    if m.TryLock() {
+        t.Errorf("TryLock should fail while readers hold the lock")
+    }
+
+    m.RUnlock()
+    m.RUnlock()
+    if !m.TryLock() {
+        t.Errorf("TryLock should succeed once the readers are gone")
+    }
+    if m.TryRLock() {
+        t.Errorf("TryRLock should fail while a writer holds the lock")
+    }
+
+    ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+    defer cancel()
+    if err := m.RLockContext(ctx); err != context.DeadlineExceeded {
+        t.Errorf("RLockContext should time out while a writer holds the lock, got %v", err)
+    }
+    m.Unlock()
+}
+
+// waitWriters waits until n writers wait for the lock
+func waitWriters(m *RWMutex, n int) {
+    for {
+        m.mu.Lock()
+        w := m.waitingWriters
+        m.mu.Unlock()
+
+        if w == n {
+            return
+        }
+        time.Sleep(time.Millisecond)
+    }
+}
+
+func TestRWMutexWriterPreferring(t *testing.T) {
+    m := NewRWMutex()
+    m.RLock()
+
+    locked := make(chan struct{})
+    go func() {
+        m.Lock()
+        close(locked)
+    }()
+    waitWriters(m, 1)
+
+    // A new reader waits behind the writer.
+    if m.TryRLock() {
+        t.Errorf("TryRLock should fail while a writer waits")
+    }
+
+    rlocked := make(chan struct{})
+    go func() {
+        m.RLock()
+        close(rlocked)
+    }()
+
+    m.RUnlock()
+    <-locked
+
+    select {
+    case <-rlocked:
+        t.Errorf("the reader should wait for the writer")
+    case <-time.After(10 * time.Millisecond):
+    }
+
+    m.Unlock()
+    <-rlocked
+    m.RUnlock()
+}
+
+func TestRWMutexLockContext(t *testing.T) {
+    m := NewRWMutex()
+    m.RLock()
+
+    // The writer gives up, the readers blocked behind it go on.
+    ctx, cancel := context.WithCancel(context.Background())
+    errc := make(chan error)
+    go func() {
+        errc <- m.LockContext(ctx)
+    }()
+    waitWriters(m, 1)
+
+    rlocked := make(chan struct{})
+    go func() {
+        m.RLock()
+        close(rlocked)
+    }()
+
+    cancel()
+    if err := <-errc; err != context.Canceled {
+        t.Errorf("LockContext should be canceled, got %v", err)
+    }
+    <-rlocked
+
+    m.RUnlock()
+    m.RUnlock()
+    if !m.TryLock() {
+        t.Errorf("the lock should be free")
+    }
+}
+
+func TestRWMutexUnlockOfUnlocked(t *testing.T) {
+    for name, unlock := range map[string]func(*RWMutex){
+        "Unlock":  (*RWMutex).Unlock,
+        "RUnlock": (*RWMutex).RUnlock,
+    } {
+        func() {
+            defer func() {
+                if recover() == nil {
+                    t.Errorf("%s of an unlocked RWMutex should panic", name)
+                }
+            }()
+
+            unlock(NewRWMutex())
+        }()
+    }
+}
+
+// waitTickets waits until n goroutines wait for the lock
+func waitTickets(m *FairMutex, n int) {
+    for {
+        m.mu.Lock()
+        w := len(m.waiters)
+        m.mu.Unlock()
+
+        if w == n {
+            return
+        }
+        time.Sleep(time.Millisecond)
+    }
+}
+
+func TestFairMutex(t *testing.T) {
+    var (
+        m     FairMutex
+        order []int
+        wg    sync.WaitGroup
+    )
+
+    m.Lock()
+    if m.TryLock() {
+        t.Errorf("TryLock should fail while the lock is held")
+    }
+
+    // The goroutines get the lock in the order they asked for it.
+    for i := 0; i < 10; i++ {
+        wg.Add(1)
+        go func(i int) {
+            defer wg.Done()
+
+            m.Lock()
+            order = append(order, i)
+            m.Unlock()
+        }(i)
+        waitTickets(&m, i+1)
+    }
+
+    m.Unlock()
+    wg.Wait()
+
+    for i, o := range order {
+        if o != i {
+            t.Fatalf("the lock should be acquired in FIFO order, got %v", order)
+        }
+    }
+
+    if !m.TryLock() {
+        t.Errorf("TryLock should succeed once all released")
+    }
+}
+
+func TestFairMutexLockContext(t *testing.T) {
+    var m FairMutex
+    m.Lock()
+
+    // The canceled ticket is skipped.
+    ctx, cancel := context.WithCancel(context.Background())
+    errc := make(chan error)
+    go func() {
+        errc <- m.LockContext(ctx)
+    }()
+    waitTickets(&m, 1)
+
+    locked := make(chan struct{})
+    go func() {
+        m.Lock()
+        close(locked)
+    }()
+    waitTickets(&m, 2)
+
+    cancel()
+    if err := <-errc; err != context.Canceled {
+        t.Errorf("LockContext should be canceled, got %v", err)
+    }
+
+    m.Unlock()
+    <-locked
+    m.Unlock()
+
+    if !m.TryLock() {
+        t.Errorf("the lock should be free")
+    }
+}
+
+func TestFairMutexUnlockOfUnlocked(t *testing.T) {
+    defer func() {
+        if recover() == nil {
+            t.Errorf("Unlock of an unlocked FairMutex should panic")
+        }
+    }()
+
+    var m FairMutex
+    m.Unlock()
+}
+
+// recordOf returns the record whose call site is in the function ending
+// with fn, the function names start with the import path of the package
+func recordOf(t *testing.T, p *Profiler, fn string) Record {
+    t.Helper()
+
+    for _, r := range p.Records() {
+        if strings.Contains(r.Site(), fn+" ") {
+            return r
+        }
+    }
+
+    t.Fatalf("no record for %s", fn)
+    return Record{}
+}
+
+func holdLock(l sync.Locker, d time.Duration) {
+    l.Lock()
+    time.Sleep(d)
+    l.Unlock()
+}
+
+func waitLock(l sync.Locker) {
+    l.Lock()
+    l.Unlock()
+}
+
+// profileLocks runs holdLock then waitLock on l while it is held
+func profileLocks(l sync.Locker) {
+    locked := make(chan struct{})
+    done := make(chan struct{})
+    go func() {
+        l.Lock()
+        close(locked)
+        time.Sleep(20 * time.Millisecond)
+        l.Unlock()
+        close(done)
+    }()
+
+    <-locked
+    waitLock(l)
+    <-done
+    holdLock(l, 10*time.Millisecond)
+}
+
+func TestProfiler(t *testing.T) {
+    p := NewProfiler()
+
+    l := NewLock()
+    l.SetProfiler(p)
+    profileLocks(l)
+
+    hold := recordOf(t, p, ".holdLock")
+    if hold.Acquisitions != 1 || hold.Contentions != 0 || hold.Hold < 10*time.Millisecond {
+        t.Errorf("holdLock should hold the lock 10ms without contention, got %+v", hold)
+    }
+
+    wait := recordOf(t, p, ".waitLock")
+    if wait.Acquisitions != 1 || wait.Contentions != 1 || wait.Wait < 10*time.Millisecond {
+        t.Errorf("waitLock should wait for the lock, got %+v", wait)
+    }
+
+    if r := p.Records(); r[0].Wait < r[len(r)-1].Wait {
+        t.Errorf("the records should be sorted by wait time")
+    }
+
+    // The failed attempts count as contentions.
+    l.Lock()
+    if l.LockTimeout(time.Millisecond) {
+        t.Fatalf("LockTimeout should fail")
+    }
+    l.Unlock()
+
+    // Each call site has its own record.
+    for _, r := range p.Records() {
+        if strings.Contains(r.Site(), ".TestProfiler ") && r.Acquisitions == 0 {
+            if r.Contentions != 1 || r.Wait < time.Millisecond {
+                t.Errorf("the failed LockTimeout should be a contention, got %+v", r)
+            }
+            return
+        }
+    }
+    t.Errorf("the failed LockTimeout should be recorded")
+}
+
+func TestProfilerLocks(t *testing.T) {
+    var fair FairMutex
+    locks := map[string]func(p *Profiler) sync.Locker{
+        "FairMutex": func(p *Profiler) sync.Locker {
+            fair.SetProfiler(p)
+            return &fair
+        },
+        "RWMutex": func(p *Profiler) sync.Locker {
+            m := NewRWMutex()
+            m.SetProfiler(p)
+            return m
+        },
+        "sync.Mutex": func(p *Profiler) sync.Locker {
+            return p.Wrap(&sync.Mutex{})
+        },
+    }
+
+    for name, newLock := range locks {
+        t.Run(name, func(t *testing.T) {
+            p := NewProfiler()
+            profileLocks(newLock(p))
+
+            if r := recordOf(t, p, ".holdLock"); r.Hold < 10*time.Millisecond {
+                t.Errorf("holdLock should hold the lock 10ms, got %+v", r)
+            }
+
+            if r := recordOf(t, p, ".waitLock"); r.Contentions != 1 || r.Wait < 10*time.Millisecond {
+                t.Errorf("waitLock should wait for the lock, got %+v", r)
+            }
+        })
+    }
+}
+
+func TestProfilerRLock(t *testing.T) {
+    p := NewProfiler()
+    m := NewRWMutex()
+    m.SetProfiler(p)
+
+    m.Lock()
+    go func() {
+        time.Sleep(10 * time.Millisecond)
+        m.Unlock()
+    }()
+    m.RLock()
+    m.RUnlock()
+
+    for _, r := range p.Records() {
+        if r.Contentions == 1 && r.Acquisitions == 1 && r.Hold == 0 && r.Wait >= 10*time.Millisecond {
+            return
+        }
+    }
+    t.Errorf("the read lock should be recorded, got %+v", p.Records())
+}
+
+func TestProfilerWriteTo(t *testing.T) {
+    p := NewProfiler()
+    l := NewLock()
+    l.SetProfiler(p)
+    profileLocks(l)
+
+    var buf bytes.Buffer
+    n, err := p.WriteTo(&buf)
+    if err != nil || n != int64(buf.Len()) {
+        t.Fatalf("WriteTo should write %d bytes, got %d, %v", buf.Len(), n, err)
+    }
+
+    zr, err := gzip.NewReader(&buf)
+    if err != nil {
+        t.Fatalf("the profile should be gzipped: %v", err)
+    }
+    data, err := io.ReadAll(zr)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    for _, s := range []string{"acquisitions", "contentions", "wait", "hold", "nanoseconds", ".holdLock", ".waitLock"} {
+        if !bytes.Contains(data, []byte(s)) {
+            t.Errorf("the profile should contain %q", s)
+        }
+    }
+}